@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/config"
+)
+
+// runAlias runs an alias defined in the config file with the given extra
+// options. An alias running a single invocation runs in this process; the
+// invocations of a longer alias run as child processes, in order, stopping
+// at the first one that does not exit with 0.
+func runAlias(name string, args []string) {
+	alias, err := config.FindAlias(name)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Invalid config file: %v", err))
+	}
+	if alias == nil {
+		exitWithError(fmt.Sprintf("Unknown command '%s' (run 'rgp -help' for the list of commands)", name))
+	}
+
+	steps, err := config.ExpandAlias(alias, args)
+	if err != nil {
+		exitWithError(err.Error())
+	}
+	if len(steps) == 1 {
+		run(steps[0])
+		return
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		exitWithError(fmt.Sprintf("Cannot run alias '%s': %v", name, err))
+	}
+
+	// Interrupts reach the running step directly; wait for it to stop
+	signal.Notify(make(chan os.Signal, 1), os.Interrupt)
+
+	for i, step := range steps {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.Info(fmt.Sprintf("[%d/%d]", i+1, len(steps))), colors.Bold("rgp "+strings.Join(step, " ")))
+
+		cmd := exec.Command(executable, step...)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+				if i < len(steps)-1 {
+					fmt.Fprintf(os.Stderr, "%s %s\n", colors.WarningIcon(), colors.Warning(fmt.Sprintf("Alias '%s' stopped after step %d of %d", name, i+1, len(steps))))
+				}
+				os.Exit(exitErr.ExitCode())
+			}
+			exitWithError(fmt.Sprintf("Cannot run alias '%s': %v", name, err))
+		}
+	}
+}
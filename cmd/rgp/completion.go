@@ -0,0 +1,316 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/config"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/finder"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/history"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// completeCommand is the hidden command the completion scripts call to get
+// candidates for the word being completed
+const completeCommand = "__complete"
+
+// The completion scripts pass the words typed after "rgp" to the hidden
+// command and fall back to file names when it prints nothing
+const bashCompletion = `# bash completion for rgp
+_rgp() {
+    local IFS=$'\n'
+    COMPREPLY=($(rgp __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _rgp rgp
+`
+
+const zshCompletion = `#compdef rgp
+compdef _rgp rgp
+
+_rgp() {
+    local -a candidates
+    candidates=("${(@f)$(rgp __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    if [[ -n ${candidates[1]} ]]; then
+        compadd -Q -S '' -a candidates
+    else
+        _files
+    fi
+}
+
+if [ "$funcstack[1]" = "_rgp" ]; then
+    _rgp "$@"
+fi
+`
+
+const fishCompletion = `# fish completion for rgp
+function __rgp_complete
+    set -l tokens (commandline -opc) (commandline -ct)
+    rgp __complete $tokens[2..-1] 2>/dev/null
+end
+complete -c rgp -a '(__rgp_complete)'
+`
+
+// completionScripts maps each supported shell to its completion script
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+// runCompletion implements the completion command:
+//
+//	rgp completion bash|zsh|fish
+func runCompletion(args []string) {
+	if len(args) != 1 || completionScripts[args[0]] == "" {
+		fmt.Println("Usage:")
+		fmt.Println("  rgp completion bash|zsh|fish")
+		fmt.Println("")
+		fmt.Println("Examples:")
+		fmt.Println("  source <(rgp completion bash)")
+		fmt.Println("  rgp completion zsh > \"${fpath[1]}/_rgp\"")
+		fmt.Println("  rgp completion fish > ~/.config/fish/completions/rgp.fish")
+		if len(args) == 1 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+			return
+		}
+		os.Exit(1)
+	}
+	fmt.Print(completionScripts[args[0]])
+}
+
+// runComplete prints the completion candidates for the last of the given
+// words, one per line
+func runComplete(words []string) {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+	for _, candidate := range completionCandidates(words[:len(words)-1], current) {
+		if strings.HasPrefix(candidate, current) {
+			fmt.Println(candidate)
+		}
+	}
+}
+
+// completionCandidates returns the possible values for the current word
+// given the words before it
+func completionCandidates(previous []string, current string) []string {
+	// The first word is a subcommand or a flag of the flat interface
+	subcommand := ""
+	if len(previous) > 0 && !strings.HasPrefix(previous[0], "-") {
+		subcommand = previous[0]
+	} else if len(previous) == 0 && !strings.HasPrefix(current, "-") {
+		return subcommandNames()
+	}
+
+	switch subcommand {
+	case "completion":
+		if len(previous) == 1 {
+			return []string{"bash", "fish", "zsh"}
+		}
+		return nil
+	case "history":
+		switch last := previous[len(previous)-1]; {
+		case strings.HasPrefix(current, "-"):
+			return []string{"-file", "-limit", "-no-color", "-path"}
+		case last == "-file" || last == "-limit" || last == "-path":
+			return nil
+		case len(previous) == 1:
+			return []string{"diff"}
+		case previous[1] == "diff":
+			return runIDs(flagValue(previous, "file", ""))
+		}
+		return nil
+	}
+
+	// Aliases complete the options of the command they run first
+	if alias, _ := config.FindAlias(subcommand); alias != nil {
+		subcommand = ""
+		if steps, err := config.ExpandAlias(alias, nil); err == nil && !strings.HasPrefix(steps[0][0], "-") {
+			subcommand = steps[0][0]
+		}
+	}
+
+	fs := config.FlagSet(subcommand)
+	if fs == nil {
+		return nil
+	}
+
+	// Complete the value of the previous flag
+	if len(previous) > 0 {
+		name := strings.TrimLeft(previous[len(previous)-1], "-")
+		if f := fs.Lookup(name); strings.HasPrefix(previous[len(previous)-1], "-") && f != nil && !isBoolFlag(f) {
+			return flagValueCandidates(name, previous, current)
+		}
+	}
+
+	if !strings.HasPrefix(current, "-") {
+		return nil
+	}
+
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, "-"+f.Name)
+	})
+	return names
+}
+
+// flagValueCandidates returns the possible values of a flag
+func flagValueCandidates(name string, previous []string, current string) []string {
+	switch name {
+	case "include", "exclude":
+		profile := flagValue(previous, "profile", "")
+		root := flagValue(previous, "path", settingOr("path", profile, "."))
+		return listCandidates(current, repositoryNames(root, flagValue(previous, "history-file", config.Setting("history-file", profile))))
+	case "profile":
+		profiles, _ := config.Profiles()
+		return profiles
+	case "dirty-states":
+		return listCandidates(current, types.AllDirtyStates)
+	case "output":
+		return []string{types.OutputSummary, types.OutputGrouped, types.OutputPrefix, types.OutputJSON}
+	case "pull-strategy":
+		return []string{types.PullStrategyFFOnly, types.PullStrategyRebase}
+	case "theme":
+		themes := make([]string, 0, len(colors.Themes))
+		for theme := range colors.Themes {
+			themes = append(themes, theme)
+		}
+		sort.Strings(themes)
+		return themes
+	case "format":
+		return []string{types.ListPlain, types.ListNUL, types.ListJSON}
+	case "columns":
+		return listCandidates(current, types.AllListColumns)
+	case "icons":
+		return []string{"auto", "ascii", "unicode"}
+	}
+	return nil
+}
+
+// listCandidates completes the last element of a comma-separated list
+func listCandidates(current string, values []string) []string {
+	prefix := ""
+	if i := strings.LastIndex(current, ","); i >= 0 {
+		prefix = current[:i+1]
+	}
+
+	candidates := make([]string, 0, len(values))
+	for _, value := range values {
+		candidates = append(candidates, prefix+value)
+	}
+	return candidates
+}
+
+// subcommandNames returns the names of all commands and aliases, sorted
+func subcommandNames() []string {
+	names := []string{"completion", "history"}
+	for _, sub := range config.Subcommands {
+		names = append(names, sub.Name)
+	}
+	aliases, _ := config.Aliases()
+	for _, alias := range aliases {
+		names = append(names, alias.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// repositoryNames returns the unique names of the repositories under root.
+// The last run recorded in the history for root serves as an index, so
+// large trees are not walked on every completion; without one, the
+// repositories are discovered.
+func repositoryNames(root, historyFile string) []string {
+	var found []string
+	if record := lastRecordedRun(root, historyFile); record != nil {
+		for _, result := range record.Results {
+			found = append(found, result.Name)
+		}
+	} else {
+		repositories, err := finder.FindRepositories(root, nil, nil)
+		if err != nil {
+			return nil
+		}
+		for _, repo := range repositories {
+			found = append(found, repo.Name)
+		}
+	}
+
+	seen := make(map[string]bool, len(found))
+	var names []string
+	for _, name := range found {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lastRecordedRun returns the last run recorded in the history for root, or nil
+func lastRecordedRun(root, historyFile string) *history.RunRecord {
+	path, err := historyPath(historyFile)
+	if err != nil {
+		return nil
+	}
+	records, err := history.Load(path)
+	if err != nil {
+		return nil
+	}
+	record, err := history.Find(history.InWorkspace(records, root), "last")
+	if err != nil {
+		return nil
+	}
+	return record
+}
+
+// runIDs returns the IDs of the runs recorded in the history, newest first,
+// after the "last" and "previous" shortcuts
+func runIDs(historyFile string) []string {
+	ids := []string{"last", "previous"}
+	path, err := historyPath(historyFile)
+	if err != nil {
+		return ids
+	}
+	records, _ := history.Load(path)
+	for i := len(records) - 1; i >= 0; i-- {
+		ids = append(ids, records[i].ID)
+	}
+	return ids
+}
+
+// settingOr returns the value of an option set in the environment or the
+// config file with the given profile, or def
+func settingOr(name, profile, def string) string {
+	if value := config.Setting(name, profile); value != "" {
+		return value
+	}
+	return def
+}
+
+// flagValue returns the last value given to a flag in words, or def
+func flagValue(words []string, name, def string) string {
+	value := def
+	for i, word := range words {
+		flagName, flagArg, hasArg := strings.Cut(strings.TrimLeft(word, "-"), "=")
+		if !strings.HasPrefix(word, "-") || flagName != name {
+			continue
+		}
+		if hasArg {
+			value = flagArg
+		} else if i+1 < len(words) {
+			value = words[i+1]
+		}
+	}
+	return value
+}
+
+// isBoolFlag checks if the flag can be given without a value
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
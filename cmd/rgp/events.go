@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/report"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// verboseEventHandler returns an event handler printing each repository
+// as it starts and finishes
+func verboseEventHandler() git.EventHandler {
+	var mu sync.Mutex
+	return func(event git.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch event.Type {
+		case git.EventStarted:
+			fmt.Printf("%s %s\n", colors.Info("Executing '"+git.DisplayCommand(event.Command)+"' in"), colors.Dim(event.Repository.Path+"..."))
+		case git.EventFinished:
+			printResult(event.Result)
+		}
+	}
+}
+
+// printResult prints the execution result with colors
+func printResult(result *types.ExecutionResult) {
+	var icon, status string
+	if result.Success {
+		icon = colors.SuccessIcon()
+		status = colors.Success(result.Repository.Name)
+	} else {
+		icon = colors.ErrorIcon()
+		status = colors.Error(result.Repository.Name)
+	}
+
+	duration := colors.Dim(fmt.Sprintf("(%v)", result.Duration))
+	fmt.Printf("%s %s %s\n", icon, status, duration)
+
+	if result.Error != "" {
+		if result.NeedsAttention || result.Skipped || result.TimedOut {
+			fmt.Printf("  %s %s\n", colors.WarningIcon(), colors.Warning(result.Error))
+		} else {
+			fmt.Printf("  %s %s\n", colors.ErrorIcon(), colors.Error(result.Error))
+		}
+	}
+
+	if output := strings.TrimSpace(result.Output); output != "" {
+		fmt.Printf("  %s %s\n", colors.InfoIcon(), colors.Dim(output))
+	}
+}
+
+// prefixEventHandler returns an event handler printing each output line as
+// "repo-name: line", serialized so lines from parallel runs never mix.
+// Lines written to stderr by git are printed to stderr.
+func prefixEventHandler() git.EventHandler {
+	var mu sync.Mutex
+	return func(event git.Event) {
+		if event.Type != git.EventOutputLine {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if event.Stderr {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.Info(event.Repository.Name+":"), event.Line)
+		} else {
+			fmt.Printf("%s %s\n", colors.Info(event.Repository.Name+":"), event.Line)
+		}
+	}
+}
+
+// jsonEvent is the JSON form of an event, written one per line
+type jsonEvent struct {
+	Type       string  `json:"type"`
+	Time       string  `json:"time"`
+	Repository string  `json:"repository"`
+	Path       string  `json:"path"`
+	Command    string  `json:"command"`
+	Line       *string `json:"line,omitempty"`
+	Stream     string  `json:"stream,omitempty"`
+	Attempt    int     `json:"attempt,omitempty"`
+	Status     string  `json:"status,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs *int64  `json:"duration_ms,omitempty"`
+
+	Changes *types.ChangeSummary `json:"changes,omitempty"`
+}
+
+// jsonEventHandler returns an event handler writing every event to stdout
+// as a line of JSON
+func jsonEventHandler() git.EventHandler {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(os.Stdout)
+	return func(event git.Event) {
+		record := jsonEvent{
+			Type:       event.Type.String(),
+			Time:       event.Time.Format(time.RFC3339Nano),
+			Repository: event.Repository.Name,
+			Path:       event.Repository.Path,
+			Command:    event.Command,
+			Attempt:    event.Attempt,
+		}
+
+		switch event.Type {
+		case git.EventOutputLine:
+			record.Line = &event.Line
+			record.Stream = "stdout"
+			if event.Stderr {
+				record.Stream = "stderr"
+			}
+		case git.EventFinished:
+			duration := event.Result.Duration.Milliseconds()
+			record.Status = report.Status(event.Result)
+			record.Error = event.Result.Error
+			record.DurationMs = &duration
+			record.Changes = event.Result.Changes
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		encoder.Encode(record)
+	}
+}
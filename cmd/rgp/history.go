@@ -0,0 +1,230 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/history"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/report"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// recordHistory appends the run to the history file unless disabled
+func recordHistory(cfg *types.Config, runReport *report.Run) {
+	if cfg.NoHistory {
+		return
+	}
+
+	path, err := historyPath(cfg.HistoryFile)
+	if err == nil {
+		err = history.Append(path, history.NewRecord(runReport))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.WarningIcon(), colors.Warning(fmt.Sprintf("Could not record run history: %v", err)))
+	}
+}
+
+// loadLastRun returns the most recent run recorded in the history for the
+// workspace at cfg.RootPath
+func loadLastRun(cfg *types.Config) *history.RunRecord {
+	path, err := historyPath(cfg.HistoryFile)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Error locating history file: %v", err))
+	}
+
+	records, err := history.Load(path)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Error reading history: %v", err))
+	}
+
+	root, err := filepath.Abs(cfg.RootPath)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Error resolving path: %v", err))
+	}
+	record, err := history.Find(history.InWorkspace(records, root), "last")
+	if err != nil {
+		exitWithError(fmt.Sprintf("No previous run recorded in the history for %s", root))
+	}
+	return record
+}
+
+// historyPath returns the configured history file or the default location
+func historyPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	return history.DefaultPath()
+}
+
+// runHistory implements the history command:
+//
+//	rgp history [-file path] [-path dir] [-limit n]
+//	rgp history diff [-file path] [-path dir] [a] [b]
+func runHistory(args []string) {
+	diff := len(args) > 0 && args[0] == "diff"
+	if diff {
+		args = args[1:]
+	}
+
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	file := flags.String("file", "", "History file (default: $XDG_DATA_HOME/rgp/history.jsonl)")
+	workspace := flags.String("path", "", "Only use runs in this workspace (default: all workspaces; for diff, the workspace of the last run)")
+	limit := flags.Int("limit", 20, "Maximum number of runs to list (0 = all)")
+	noColor := flags.Bool("no-color", false, "Disable colored output")
+	flags.Usage = func() {
+		fmt.Println("Usage:")
+		fmt.Println("  rgp history [options]              List recorded runs")
+		fmt.Println("  rgp history diff [options] [a] [b] Compare two runs (default: previous and last)")
+		fmt.Println("")
+		fmt.Println("Options:")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *noColor {
+		colors.SetForceNoColor(true)
+	}
+
+	path, err := historyPath(*file)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Error locating history file: %v", err))
+	}
+
+	records, err := history.Load(path)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Error reading history: %v", err))
+	}
+
+	if *workspace != "" {
+		records = history.InWorkspace(records, *workspace)
+	}
+
+	if !diff {
+		printHistory(records, *limit)
+		return
+	}
+
+	ids := flags.Args()
+	a, b := "previous", "last"
+	switch len(ids) {
+	case 0:
+	case 1:
+		a = ids[0]
+	case 2:
+		a, b = ids[0], ids[1]
+	default:
+		exitWithError("history diff expects at most two run IDs")
+	}
+
+	// Without a workspace, compare the last runs of the most recent workspace
+	if *workspace == "" && len(ids) == 0 && len(records) > 0 {
+		records = history.InWorkspace(records, records[len(records)-1].RootPath)
+	}
+
+	before, err := history.Find(records, a)
+	if err != nil {
+		exitWithError(err.Error())
+	}
+	after, err := history.Find(records, b)
+	if err != nil {
+		exitWithError(err.Error())
+	}
+	if before.RootPath != after.RootPath {
+		exitWithError(fmt.Sprintf("Runs %s and %s are from different workspaces (%s and %s)", before.ID, after.ID, before.RootPath, after.RootPath))
+	}
+	printHistoryDiff(before, after)
+}
+
+// printHistory lists the most recent runs, newest last
+func printHistory(records []*history.RunRecord, limit int) {
+	if len(records) == 0 {
+		fmt.Printf("%s %s\n", colors.WarningIcon(), colors.Warning("No runs recorded yet."))
+		return
+	}
+
+	if limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+
+	for _, record := range records {
+		failed := record.Count(report.StatusFailed)
+		skipped := record.Count(report.StatusSkipped)
+		counts := colors.Success(fmt.Sprintf("%d ok", len(record.Results)-failed-skipped))
+		if failed > 0 {
+			counts += " " + colors.Error(fmt.Sprintf("%d failed", failed))
+		}
+		if skipped > 0 {
+			counts += " " + colors.Warning(fmt.Sprintf("%d skipped", skipped))
+		}
+
+		fmt.Printf("%s  %s  %s  %s  %s %s\n",
+			colors.Bold(record.ID),
+			colors.Dim(record.Started.Format("2006-01-02 15:04:05")),
+			colors.Dim(record.RootPath),
+			colors.Info(git.DisplayCommand(record.Command)),
+			counts,
+			colors.Dim(fmt.Sprintf("(%v)", record.Duration.Round(time.Millisecond))))
+	}
+}
+
+// printHistoryDiff prints the differences between two runs
+func printHistoryDiff(before, after *history.RunRecord) {
+	diff := history.Compare(before, after)
+
+	fmt.Printf("%s %s %s %s %s\n", colors.Bold("Comparing"), colors.Info(before.ID), colors.Bold("->"), colors.Info(after.ID), colors.Dim("in "+after.RootPath))
+
+	empty := true
+	if len(diff.NewlyFailed) > 0 {
+		empty = false
+		fmt.Printf("\n%s\n", colors.Bold(fmt.Sprintf("Newly failed (%d):", len(diff.NewlyFailed))))
+		for _, change := range diff.NewlyFailed {
+			fmt.Printf("%s %s\n", colors.ErrorIcon(), colors.Error(change.Name))
+			if change.After.Error != "" {
+				fmt.Printf("  %s %s\n", colors.ErrorIcon(), colors.Error(change.After.Error))
+			}
+		}
+	}
+
+	if len(diff.Recovered) > 0 {
+		empty = false
+		fmt.Printf("\n%s\n", colors.Bold(fmt.Sprintf("Recovered (%d):", len(diff.Recovered))))
+		for _, change := range diff.Recovered {
+			fmt.Printf("%s %s\n", colors.SuccessIcon(), colors.Success(change.Name))
+		}
+	}
+
+	if len(diff.DurationChanged) > 0 {
+		empty = false
+		fmt.Printf("\n%s\n", colors.Bold(fmt.Sprintf("Duration changed (%d):", len(diff.DurationChanged))))
+		for _, change := range diff.DurationChanged {
+			fmt.Printf("%s %s %s\n", colors.InfoIcon(), colors.Bold(change.Name),
+				colors.Dim(fmt.Sprintf("%v -> %v", change.Before.Duration.Round(time.Millisecond), change.After.Duration.Round(time.Millisecond))))
+		}
+	}
+
+	if len(diff.Added) > 0 || len(diff.Removed) > 0 {
+		empty = false
+		fmt.Printf("\n%s\n", colors.Bold("Repository set changed:"))
+		for _, record := range diff.Added {
+			fmt.Printf("  %s %s\n", colors.Success("+"), record.Name)
+		}
+		for _, record := range diff.Removed {
+			fmt.Printf("  %s %s\n", colors.Error("-"), record.Name)
+		}
+	}
+
+	if empty {
+		fmt.Printf("\n%s %s\n", colors.SuccessIcon(), colors.Success("No significant differences."))
+	}
+}
+
+// exitWithError prints an error message and exits with status 1
+func exitWithError(message string) {
+	fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(message))
+	os.Exit(1)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/monitor"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/selector"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/terminal"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// selectRepositories lets the user pick repositories from a checklist,
+// starting from the last selection made in the same workspace
+func selectRepositories(cfg *types.Config, repositories []*types.Repository) []*types.Repository {
+	if !terminal.IsTerminal(os.Stdin) || !terminal.IsTerminal(os.Stderr) {
+		exitWithError("Interactive selection requires a terminal")
+	}
+
+	path, err := selector.DefaultPath()
+	if err != nil {
+		exitWithError(fmt.Sprintf("Error locating selection file: %v", err))
+	}
+
+	previous, err := selector.Load(path, cfg.RootPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.WarningIcon(), colors.Warning(fmt.Sprintf("Could not load the last selection: %v", err)))
+	}
+
+	selected, err := selector.Select(repositories, cfg.RootPath, previous)
+	if err == selector.ErrCancelled {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.WarningIcon(), colors.Warning("Selection cancelled"))
+		os.Exit(130)
+	} else if err != nil {
+		exitWithError(fmt.Sprintf("Error selecting repositories: %v", err))
+	}
+
+	if err := selector.Save(path, cfg.RootPath, selected); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.WarningIcon(), colors.Warning(fmt.Sprintf("Could not remember the selection: %v", err)))
+	}
+	return selected
+}
+
+// monitorRun executes the command while showing the full-screen monitor
+func monitorRun(executor *git.Executor, repositories []*types.Repository, command string) []*types.ExecutionResult {
+	if !terminal.IsTerminal(os.Stdin) || !terminal.IsTerminal(os.Stderr) {
+		exitWithError("The full-screen view requires a terminal")
+	}
+
+	results, err := monitor.Run(context.Background(), executor, repositories, command)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Error running the full-screen view: %v", err))
+	}
+	return results
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/finder"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// Kinds of repositories in the list
+const (
+	kindRepository = "repository"
+	kindNested     = "nested"
+)
+
+// runList prints the repositories matching the filters in the configured
+// format, one record per repository
+func runList(cfg *types.Config) {
+	if cfg.NoColor {
+		colors.SetForceNoColor(true)
+	}
+
+	repositories, err := finder.FindRepositories(cfg.RootPath, cfg.IncludePatterns, cfg.ExcludePatterns)
+	if err != nil {
+		exitWithError(fmt.Sprintf("Error finding repositories: %v", err))
+	}
+
+	sort.Slice(repositories, func(i, j int) bool {
+		return repositories[i].Path < repositories[j].Path
+	})
+
+	columns := cfg.ListColumns
+	if len(columns) == 0 {
+		columns = []string{types.ColumnPath}
+	}
+
+	executor := git.NewExecutor(cfg)
+	records := make([]map[string]string, 0, len(repositories))
+	for _, repo := range repositories {
+		record := make(map[string]string, len(columns))
+		for _, column := range columns {
+			record[column] = listColumn(executor, cfg.RootPath, repositories, repo, column)
+		}
+		records = append(records, record)
+	}
+
+	switch cfg.ListFormat {
+	case types.ListJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(records); err != nil {
+			exitWithError(fmt.Sprintf("Error writing list: %v", err))
+		}
+	default:
+		terminator := "\n"
+		if cfg.ListFormat == types.ListNUL {
+			terminator = "\x00"
+		}
+		for _, record := range records {
+			fields := make([]string, 0, len(columns))
+			for _, column := range columns {
+				fields = append(fields, record[column])
+			}
+			fmt.Print(strings.Join(fields, "\t") + terminator)
+		}
+	}
+
+	if len(repositories) == 0 {
+		os.Exit(cfg.ExitCodes.NothingFound)
+	}
+}
+
+// listColumn returns the value of a list column for a repository
+func listColumn(executor *git.Executor, root string, repositories []*types.Repository, repo *types.Repository, column string) string {
+	switch column {
+	case types.ColumnName:
+		return repo.Name
+	case types.ColumnRelative:
+		if relative, err := filepath.Rel(root, repo.Path); err == nil {
+			return relative
+		}
+		return repo.Path
+	case types.ColumnBranch:
+		return executor.CurrentBranch(context.Background(), repo.Path)
+	case types.ColumnRemote:
+		return executor.RemoteURL(context.Background(), repo.Path)
+	case types.ColumnKind:
+		if isNested(repositories, repo) {
+			return kindNested
+		}
+		return kindRepository
+	default:
+		if absolute, err := filepath.Abs(repo.Path); err == nil {
+			return absolute
+		}
+		return repo.Path
+	}
+}
+
+// isNested checks if the repository is inside another listed repository
+func isNested(repositories []*types.Repository, repo *types.Repository) bool {
+	for _, other := range repositories {
+		if other != repo && strings.HasPrefix(repo.Path, other.Path+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sort"
@@ -11,104 +12,304 @@ import (
 	"github.com/robsonalvesdevbr/recursive-git-pull/internal/config"
 	"github.com/robsonalvesdevbr/recursive-git-pull/internal/finder"
 	"github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/history"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/report"
 	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
 )
 
 func main() {
-	cfg := config.ParseFlags()
-	
+	run(os.Args[1:])
+}
+
+// run runs the rgp invocation given by the command line arguments
+func run(args []string) {
+	var cfg *types.Config
+	switch name := subcommandName(args); name {
+	case "":
+		cfg = config.ParseArgs(args)
+	case "history":
+		runHistory(args[1:])
+		return
+	case "completion":
+		runCompletion(args[1:])
+		return
+	case completeCommand:
+		runComplete(args[1:])
+		return
+	case "list":
+		runList(config.ParseSubcommand(name, args[1:]))
+		return
+	case "retry-failed":
+		cfg = config.ParseSubcommand(name, args[1:])
+	default:
+		if config.FindSubcommand(name) == nil {
+			runAlias(name, args[1:])
+			return
+		}
+		cfg = config.ParseSubcommand(name, args[1:])
+	}
+
 	// Set color preferences
 	if cfg.NoColor {
 		colors.SetForceNoColor(true)
 	}
 
+	// Load the repositories that failed in the last run
+	var lastRun *history.RunRecord
+	if cfg.OnlyFailed {
+		lastRun = loadLastRun(cfg)
+		cfg.Command = lastRun.Command
+		cfg.RootPath = lastRun.RootPath
+	}
+
 	if cfg.Verbose {
 		fmt.Printf("%s\n", colors.Bold("Starting recursive git command execution..."))
 		fmt.Printf("%s %s\n", colors.Info("Root path:"), colors.Dim(cfg.RootPath))
-		fmt.Printf("%s %s\n", colors.Info("Command:"), colors.Bold("git "+cfg.Command))
+		fmt.Printf("%s %s\n", colors.Info("Command:"), colors.Bold(git.DisplayCommand(cfg.Command)))
 		fmt.Printf("%s %s\n", colors.Info("Parallel:"), colors.Bold(fmt.Sprintf("%t", cfg.Parallel)))
 		if cfg.Parallel {
 			fmt.Printf("%s %s\n", colors.Info("Max workers:"), colors.Bold(fmt.Sprintf("%d", cfg.MaxWorkers)))
 		}
 		fmt.Printf("%s %s\n", colors.Info("Timeout:"), colors.Bold(fmt.Sprintf("%v", cfg.Timeout)))
+		if cfg.RepoTimeout > 0 {
+			fmt.Printf("%s %s\n", colors.Info("Repository timeout:"), colors.Bold(fmt.Sprintf("%v", cfg.RepoTimeout)))
+		}
+		if cfg.RunTimeout > 0 {
+			fmt.Printf("%s %s\n", colors.Info("Run timeout:"), colors.Bold(fmt.Sprintf("%v", cfg.RunTimeout)))
+		}
 		fmt.Println()
 	}
 
-	// Find all Git repositories
-	repositories, err := finder.FindRepositories(cfg.RootPath, cfg.IncludePatterns, cfg.ExcludePatterns)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Error finding repositories: %v", err)))
-		os.Exit(1)
+	// Find all Git repositories, or retry the failed ones
+	var repositories []*types.Repository
+	var err error
+	if lastRun != nil {
+		repositories = lastRun.FailedRepositories()
+		if len(repositories) == 0 {
+			fmt.Printf("%s %s\n", colors.SuccessIcon(), colors.Success(fmt.Sprintf("No failed repositories in run %s.", lastRun.ID)))
+			os.Exit(0)
+		}
+	} else {
+		repositories, err = finder.FindRepositories(cfg.RootPath, cfg.IncludePatterns, cfg.ExcludePatterns)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Error finding repositories: %v", err)))
+			os.Exit(1)
+		}
 	}
 
+	// Let the user pick the repositories to run on
+	if cfg.Interactive && len(repositories) > 0 {
+		repositories = selectRepositories(cfg, repositories)
+	}
+
+	// Keep stdout limited to the command output in prefix, JSON and quiet modes
+	streamed := cfg.OutputMode == types.OutputPrefix || cfg.OutputMode == types.OutputJSON
+
 	if len(repositories) == 0 {
-		fmt.Printf("%s %s\n", colors.WarningIcon(), colors.Warning("No Git repositories found in the specified path."))
-		os.Exit(0)
+		if !cfg.Quiet && !streamed {
+			fmt.Printf("%s %s\n", colors.WarningIcon(), colors.Warning("No Git repositories found in the specified path."))
+		}
+		os.Exit(cfg.ExitCodes.NothingFound)
 	}
 
-	fmt.Printf("%s %s\n", colors.SuccessIcon(), colors.Success(fmt.Sprintf("Found %d repositories:", len(repositories))))
-	for _, repo := range repositories {
-		fmt.Printf("  %s %s %s\n", colors.Info("•"), colors.Bold(repo.Name), colors.Dim(fmt.Sprintf("(%s)", repo.Path)))
+	if !streamed && !cfg.Quiet {
+		if lastRun != nil {
+			fmt.Printf("%s %s\n", colors.SuccessIcon(), colors.Success(fmt.Sprintf("Retrying '%s' on %d repositories that failed in run %s:", git.DisplayCommand(cfg.Command), len(repositories), lastRun.ID)))
+		} else if cfg.Interactive {
+			fmt.Printf("%s %s\n", colors.SuccessIcon(), colors.Success(fmt.Sprintf("Selected %d repositories:", len(repositories))))
+		} else {
+			fmt.Printf("%s %s\n", colors.SuccessIcon(), colors.Success(fmt.Sprintf("Found %d repositories:", len(repositories))))
+		}
+		for _, repo := range repositories {
+			fmt.Printf("  %s %s %s\n", colors.BulletIcon(), colors.Bold(repo.Name), colors.Dim(fmt.Sprintf("(%s)", repo.Path)))
+		}
+		fmt.Println()
 	}
-	fmt.Println()
 
 	// Execute command on all repositories
 	executor := git.NewExecutor(cfg)
+	switch {
+	case cfg.OutputMode == types.OutputPrefix:
+		executor.AddEventHandler(prefixEventHandler())
+	case cfg.OutputMode == types.OutputJSON:
+		executor.AddEventHandler(jsonEventHandler())
+	case cfg.Verbose:
+		executor.AddEventHandler(verboseEventHandler())
+	}
 	start := time.Now()
 	
-	results := executor.ExecuteCommandOnRepositories(repositories, cfg.Command)
+	var results []*types.ExecutionResult
+	if cfg.TUI {
+		results = monitorRun(executor, repositories, cfg.Command)
+	} else {
+		results = executor.ExecuteCommandOnRepositories(context.Background(), repositories, cfg.Command)
+	}
 	
 	totalDuration := time.Since(start)
 
+	runReport := &report.Run{
+		Command:  cfg.Command,
+		RootPath: cfg.RootPath,
+		Started:  start,
+		Duration: totalDuration,
+		Results:  results,
+	}
+
+	// Write per-repository logs before the summary so it can link to them
+	logDir := ""
+	if cfg.LogDir != "" {
+		var err error
+		if logDir, err = report.WriteLogDir(cfg.LogDir, runReport); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Error writing logs: %v", err)))
+			os.Exit(1)
+		}
+	}
+
 	// Print summary
-	printSummary(results, totalDuration, cfg.Verbose)
+	switch {
+	case cfg.OutputMode == types.OutputPrefix:
+		printPrefixFailures(results)
+	case cfg.OutputMode == types.OutputJSON:
+		// Results were streamed as finished events
+	case cfg.Quiet:
+		printFailures(results)
+	case cfg.OutputMode == types.OutputGrouped:
+		printGroupedOutput(results, cfg.SkipEmpty)
+		printTotals(results, totalDuration)
+	default:
+		printSummary(results, totalDuration, cfg.Verbose)
+	}
 
-	// Exit with error code if any command failed
+	if logDir != "" && !cfg.Quiet {
+		fmt.Printf("%s %s\n", colors.InfoIcon(), colors.Dim(fmt.Sprintf("Logs written to %s", logDir)))
+	}
+
+	// Write reports
+	writeReports(cfg, runReport)
+	recordHistory(cfg, runReport)
+
+	// Exit with the code matching the outcome of the run
+	os.Exit(exitCode(cfg.ExitCodes, results))
+}
+
+// subcommandName returns the subcommand given as the first argument, or an
+// empty string when rgp is called with flags only
+func subcommandName(args []string) string {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return ""
+	}
+	return args[0]
+}
+
+// exitCode determines the process exit code from the results
+func exitCode(codes types.ExitCodes, results []*types.ExecutionResult) int {
+	failed, skipped, timedOut := 0, 0, 0
 	for _, result := range results {
-		if !result.Success {
+		switch {
+		case result.Skipped:
+			skipped++
+		case !result.Success:
+			failed++
+			if result.TimedOut {
+				timedOut++
+			}
+		}
+	}
+
+	switch {
+	case len(results) > 0 && timedOut == len(results):
+		return codes.TimedOut
+	case failed > 0:
+		return codes.Failed
+	case skipped > 0:
+		return codes.Skipped
+	default:
+		return 0
+	}
+}
+
+// writeReports writes every report file requested in the configuration
+func writeReports(cfg *types.Config, runReport *report.Run) {
+	reports := []struct {
+		name  string
+		path  string
+		write func(string, *report.Run) error
+	}{
+		{"JUnit", cfg.JUnitReport, report.WriteJUnitFile},
+		{"Markdown", cfg.MarkdownReport, report.WriteMarkdownFile},
+		{"HTML", cfg.HTMLReport, report.WriteHTMLFile},
+	}
+
+	for _, r := range reports {
+		if r.path == "" {
+			continue
+		}
+		if err := r.write(r.path, runReport); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Error writing %s report: %v", r.name, err)))
 			os.Exit(1)
 		}
+		if !cfg.Quiet {
+			fmt.Printf("%s %s\n", colors.InfoIcon(), colors.Dim(fmt.Sprintf("%s report written to %s", r.name, r.path)))
+		}
 	}
 }
 
 func printSummary(results []*types.ExecutionResult, totalDuration time.Duration, verbose bool) {
-	successful := 0
-	failed := 0
-
 	// Sort results by repository name for consistent output
+	sortResults(results)
+
+	fmt.Printf("%s\n", colors.Bold("Summary:"))
+	fmt.Printf("%s\n", colors.Dim("========"))
+
+	if hasChanges(results) {
+		printChangeGroups(results, verbose)
+	} else {
+		for _, result := range results {
+			printSummaryResult(result, verbose)
+		}
+	}
+
+	printTotals(results, totalDuration)
+}
+
+// sortResults sorts results by repository name
+func sortResults(results []*types.ExecutionResult) {
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].Repository.Name < results[j].Repository.Name
 	})
+}
 
-	fmt.Printf("%s\n", colors.Bold("Summary:"))
-	fmt.Printf("%s\n", colors.Dim("========"))
+// printTotals prints the number of successful, failed and skipped repositories
+func printTotals(results []*types.ExecutionResult, totalDuration time.Duration) {
+	successful := 0
+	failed := 0
+	skipped := 0
+	needsAttention := 0
+	aborted := 0
 
 	for _, result := range results {
-		if result.Success {
+		switch {
+		case result.Success:
 			successful++
-			duration := colors.Dim(fmt.Sprintf("(%v)", result.Duration))
-			fmt.Printf("%s %s %s\n", colors.SuccessIcon(), colors.Success(result.Repository.Name), duration)
-		} else {
+		case result.Skipped:
+			skipped++
+			if result.Aborted {
+				aborted++
+			}
+		default:
 			failed++
-			duration := colors.Dim(fmt.Sprintf("(%v)", result.Duration))
-			fmt.Printf("%s %s %s\n", colors.ErrorIcon(), colors.Error(result.Repository.Name), duration)
-			if result.Error != "" {
-				if strings.Contains(result.Error, "skipped") {
-					fmt.Printf("  %s %s\n", colors.WarningIcon(), colors.Warning(result.Error))
-				} else {
-					fmt.Printf("  %s %s\n", colors.ErrorIcon(), colors.Error(result.Error))
-				}
+			if result.NeedsAttention {
+				needsAttention++
 			}
 		}
-
-		if verbose && result.Output != "" {
-			fmt.Printf("  %s %s\n", colors.InfoIcon(), colors.Dim(result.Output))
-		}
 	}
 
 	totalInfo := fmt.Sprintf("Total: %d repositories processed in %v", len(results), totalDuration)
 	successInfo := fmt.Sprintf("Successful: %d", successful)
 	failedInfo := fmt.Sprintf("Failed: %d", failed)
+	skippedInfo := fmt.Sprintf("Skipped: %d", skipped)
+	attentionInfo := fmt.Sprintf("Needs attention: %d", needsAttention)
+	abortedInfo := fmt.Sprintf("Skipped due to abort: %d", aborted)
 
 	fmt.Printf("\n%s\n", colors.Bold(totalInfo))
 	if successful > 0 {
@@ -116,6 +317,100 @@ func printSummary(results []*types.ExecutionResult, totalDuration time.Duration,
 	}
 	if failed > 0 {
 		fmt.Printf("%s\n", colors.Error(failedInfo))
+		if needsAttention > 0 {
+			fmt.Printf("%s\n", colors.Warning(attentionInfo))
+		}
+	}
+	if skipped > 0 {
+		fmt.Printf("%s\n", colors.Warning(skippedInfo))
+		if aborted > 0 {
+			fmt.Printf("%s\n", colors.Warning(abortedInfo))
+		}
+	}
+
+	switch {
+	case failed > 0:
 		fmt.Printf("\n%s %s\n", colors.WarningIcon(), colors.Warning("Some repositories failed. Check the errors above."))
+	case skipped > 0:
+		fmt.Printf("\n%s %s\n", colors.WarningIcon(), colors.Warning("Some repositories were skipped. Check the reasons above."))
 	}
-}
\ No newline at end of file
+}
+
+// printSummaryResult prints a single result line of the summary
+func printSummaryResult(result *types.ExecutionResult, verbose bool) {
+	duration := colors.Dim(fmt.Sprintf("(%v)", result.Duration))
+	if result.Success {
+		fmt.Printf("%s %s %s\n", colors.SuccessIcon(), colors.Success(result.Repository.Name), duration)
+		if result.Changes.Updated() {
+			fmt.Printf("  %s %s\n", colors.InfoIcon(), report.DescribeChanges(result.Changes))
+		}
+	} else {
+		if result.Skipped {
+			fmt.Printf("%s %s %s\n", colors.WarningIcon(), colors.Warning(result.Repository.Name), duration)
+		} else {
+			fmt.Printf("%s %s %s\n", colors.ErrorIcon(), colors.Error(result.Repository.Name), duration)
+		}
+		if result.Error != "" {
+			if result.NeedsAttention || result.Skipped {
+				fmt.Printf("  %s %s\n", colors.WarningIcon(), colors.Warning(result.Error))
+			} else {
+				fmt.Printf("  %s %s\n", colors.ErrorIcon(), colors.Error(result.Error))
+			}
+		}
+		if result.LogFile != "" && !result.Skipped {
+			fmt.Printf("  %s %s %s\n", colors.InfoIcon(), colors.Info("Log:"), colors.Dim(result.LogFile))
+		}
+	}
+
+	if verbose && result.Output != "" {
+		fmt.Printf("  %s %s\n", colors.InfoIcon(), colors.Dim(result.Output))
+	}
+}
+
+// hasChanges checks if HEAD changes were recorded for any result
+func hasChanges(results []*types.ExecutionResult) bool {
+	for _, result := range results {
+		if result.Changes != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// printChangeGroups prints results grouped into updated, up to date, failed
+// and skipped repositories
+func printChangeGroups(results []*types.ExecutionResult, verbose bool) {
+	var updated, upToDate, failed, skipped []*types.ExecutionResult
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			skipped = append(skipped, result)
+		case !result.Success:
+			failed = append(failed, result)
+		case result.Changes.Updated():
+			updated = append(updated, result)
+		default:
+			upToDate = append(upToDate, result)
+		}
+	}
+
+	groups := []struct {
+		title   string
+		results []*types.ExecutionResult
+	}{
+		{fmt.Sprintf("Updated (%d):", len(updated)), updated},
+		{fmt.Sprintf("Already up to date (%d):", len(upToDate)), upToDate},
+		{fmt.Sprintf("Failed (%d):", len(failed)), failed},
+		{fmt.Sprintf("Skipped (%d):", len(skipped)), skipped},
+	}
+
+	for _, group := range groups {
+		if len(group.results) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s\n", colors.Bold(group.title))
+		for _, result := range group.results {
+			printSummaryResult(result, verbose)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// printGroupedOutput prints each repository's output as a block under a
+// header, ordered by repository name
+func printGroupedOutput(results []*types.ExecutionResult, skipEmpty bool) {
+	sortResults(results)
+
+	printed := 0
+	for _, result := range results {
+		output := strings.TrimRight(result.Output, "\n")
+		if skipEmpty && strings.TrimSpace(output) == "" && result.Success {
+			continue
+		}
+
+		if printed > 0 {
+			fmt.Println()
+		}
+		printed++
+
+		duration := colors.Dim(fmt.Sprintf("(%v)", result.Duration))
+		path := colors.Dim(result.Repository.Path)
+		if result.Success {
+			fmt.Printf("%s %s %s %s\n", colors.SuccessIcon(), colors.Success(result.Repository.Name), path, duration)
+		} else if result.Skipped {
+			fmt.Printf("%s %s %s %s\n", colors.WarningIcon(), colors.Warning(result.Repository.Name), path, duration)
+			fmt.Printf("%s %s\n", colors.WarningIcon(), colors.Warning(result.Error))
+		} else {
+			fmt.Printf("%s %s %s %s\n", colors.ErrorIcon(), colors.Error(result.Repository.Name), path, duration)
+			if result.Error != "" {
+				fmt.Printf("%s %s\n", colors.ErrorIcon(), colors.Error(result.Error))
+			}
+		}
+
+		if output != "" {
+			fmt.Println(output)
+		}
+	}
+}
+
+// printFailures prints only the repositories that failed
+func printFailures(results []*types.ExecutionResult) {
+	sortResults(results)
+
+	for _, result := range results {
+		if !result.Success && !result.Skipped {
+			printSummaryResult(result, false)
+		}
+	}
+}
+
+// printPrefixFailures reports failed repositories on stderr, keeping stdout
+// limited to the prefixed command output
+func printPrefixFailures(results []*types.ExecutionResult) {
+	sortResults(results)
+
+	for _, result := range results {
+		if result.Success || result.Error == "" {
+			continue
+		}
+		if result.Skipped {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.Warning(result.Repository.Name+":"), colors.Warning(result.Error))
+		} else {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error(result.Repository.Name+":"), colors.Error(result.Error))
+		}
+	}
+}
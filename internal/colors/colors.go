@@ -3,6 +3,7 @@ package colors
 import (
 	"fmt"
 	"os"
+	"strings"
 )
 
 // ANSI color codes
@@ -25,36 +26,161 @@ const (
 	BoldWhite  = "\033[1;97m"
 )
 
-var forceNoColor bool
+// Theme holds the escape sequences used for each kind of message
+type Theme struct {
+	Success string
+	Error   string
+	Warning string
+	Info    string
+	Dim     string
+	Bold    string
+}
+
+// Themes lists the available color themes by name
+var Themes = map[string]Theme{
+	// default uses the basic bold ANSI colors
+	"default": {
+		Success: BoldGreen,
+		Error:   BoldRed,
+		Warning: BoldYellow,
+		Info:    BoldBlue,
+		Dim:     Gray,
+		Bold:    BoldWhite,
+	},
+	// 256 uses the xterm 256-color palette
+	"256": {
+		Success: "\033[1;38;5;34m",
+		Error:   "\033[1;38;5;160m",
+		Warning: "\033[1;38;5;214m",
+		Info:    "\033[1;38;5;33m",
+		Dim:     "\033[38;5;245m",
+		Bold:    "\033[1;38;5;255m",
+	},
+	// truecolor uses 24-bit RGB colors
+	"truecolor": {
+		Success: "\033[1;38;2;46;160;67m",
+		Error:   "\033[1;38;2;218;54;51m",
+		Warning: "\033[1;38;2;210;153;34m",
+		Info:    "\033[1;38;2;56;139;253m",
+		Dim:     "\033[38;2;139;148;158m",
+		Bold:    "\033[1;38;2;240;246;252m",
+	},
+	// colorblind uses the Okabe-Ito palette, avoiding red/green pairs
+	"colorblind": {
+		Success: "\033[1;38;2;0;114;178m",
+		Error:   "\033[1;38;2;213;94;0m",
+		Warning: "\033[1;38;2;230;159;0m",
+		Info:    "\033[1;38;2;86;180;233m",
+		Dim:     "\033[38;2;153;153;153m",
+		Bold:    "\033[1;97m",
+	},
+}
+
+// IconSet holds the symbols printed in front of messages
+type IconSet struct {
+	Success string
+	Error   string
+	Warning string
+	Info    string
+	Bullet  string
+}
+
+// Icon sets selected with SetIcons
+var (
+	UnicodeIcons = IconSet{Success: "✓", Error: "✗", Warning: "⚠", Info: "ℹ", Bullet: "•"}
+	ASCIIIcons   = IconSet{Success: "+", Error: "x", Warning: "!", Info: "i", Bullet: "*"}
+)
+
+var (
+	forceNoColor bool
+	theme        = Themes["default"]
+	icons        = detectIcons()
+)
 
 // SetForceNoColor allows disabling colors programmatically
 func SetForceNoColor(noColor bool) {
 	forceNoColor = noColor
 }
 
+// SetTheme selects the color theme by name
+func SetTheme(name string) error {
+	selected, ok := Themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme '%s'", name)
+	}
+	theme = selected
+	return nil
+}
+
+// SetIcons selects the icon set: "unicode", "ascii" or "auto" to pick
+// ASCII icons when the locale is not UTF-8
+func SetIcons(mode string) error {
+	switch mode {
+	case "auto":
+		icons = detectIcons()
+	case "unicode":
+		icons = UnicodeIcons
+	case "ascii":
+		icons = ASCIIIcons
+	default:
+		return fmt.Errorf("unknown icon set '%s'", mode)
+	}
+	return nil
+}
+
+// detectIcons picks Unicode icons when the locale uses UTF-8
+func detectIcons() IconSet {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			value = strings.ToLower(value)
+			if strings.Contains(value, "utf-8") || strings.Contains(value, "utf8") {
+				return UnicodeIcons
+			}
+			return ASCIIIcons
+		}
+	}
+	return ASCIIIcons
+}
+
+// colorsForced checks FORCE_COLOR and CLICOLOR_FORCE, which keep colors
+// enabled even when output is piped
+func colorsForced() bool {
+	for _, name := range []string{"FORCE_COLOR", "CLICOLOR_FORCE"} {
+		if value := os.Getenv(name); value != "" && value != "0" && value != "false" {
+			return true
+		}
+	}
+	return false
+}
+
 // ColorsEnabled checks if colors should be displayed
 func ColorsEnabled() bool {
 	// Check if colors are force-disabled
 	if forceNoColor {
 		return false
 	}
-	
+
+	// Check if colors are forced through the environment
+	if colorsForced() {
+		return true
+	}
+
 	// Check if output is being piped or redirected
 	if fileInfo, _ := os.Stdout.Stat(); (fileInfo.Mode() & os.ModeCharDevice) == 0 {
 		return false
 	}
-	
+
 	// Check NO_COLOR environment variable
 	if os.Getenv("NO_COLOR") != "" {
 		return false
 	}
-	
+
 	// Check TERM environment variable
 	term := os.Getenv("TERM")
 	if term == "" || term == "dumb" {
 		return false
 	}
-	
+
 	return true
 }
 
@@ -68,52 +194,57 @@ func Colorize(color, text string) string {
 
 // Success returns green colored text for success messages
 func Success(text string) string {
-	return Colorize(BoldGreen, text)
+	return Colorize(theme.Success, text)
 }
 
 // Error returns red colored text for error messages
 func Error(text string) string {
-	return Colorize(BoldRed, text)
+	return Colorize(theme.Error, text)
 }
 
 // Warning returns yellow colored text for warning messages
 func Warning(text string) string {
-	return Colorize(BoldYellow, text)
+	return Colorize(theme.Warning, text)
 }
 
 // Info returns blue colored text for info messages
 func Info(text string) string {
-	return Colorize(BoldBlue, text)
+	return Colorize(theme.Info, text)
 }
 
 // Dim returns gray colored text for less important messages
 func Dim(text string) string {
-	return Colorize(Gray, text)
+	return Colorize(theme.Dim, text)
 }
 
 // Bold returns bold white text
 func Bold(text string) string {
-	return Colorize(BoldWhite, text)
+	return Colorize(theme.Bold, text)
 }
 
 // SuccessIcon returns a green checkmark symbol
 func SuccessIcon() string {
-	return Success("✓")
+	return Success(icons.Success)
 }
 
 // ErrorIcon returns a red X symbol
 func ErrorIcon() string {
-	return Error("✗")
+	return Error(icons.Error)
 }
 
 // WarningIcon returns a yellow warning symbol
 func WarningIcon() string {
-	return Warning("⚠")
+	return Warning(icons.Warning)
 }
 
 // InfoIcon returns a blue info symbol
 func InfoIcon() string {
-	return Info("ℹ")
+	return Info(icons.Info)
+}
+
+// BulletIcon returns a blue list bullet
+func BulletIcon() string {
+	return Info(icons.Bullet)
 }
 
 // Printf with color support
@@ -124,4 +255,4 @@ func Printf(color, format string, args ...interface{}) {
 // Println with color support
 func Println(color, text string) {
 	fmt.Println(Colorize(color, text))
-}
\ No newline at end of file
+}
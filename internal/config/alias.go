@@ -0,0 +1,291 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// aliasSection is the config file section defining command aliases
+const aliasSection = "alias"
+
+// stepSeparator separates the invocations of an alias running several
+// commands in sequence
+const stepSeparator = "&&"
+
+// Alias is a command defined in the [alias] section of the config file, as
+// one or more rgp invocations separated by &&:
+//
+//	[alias]
+//	sync = run -workers 16 -- fetch --all --prune
+//	morning = sync && status -quiet -- --short --branch
+//	update = pull --ff-only -autostash
+type Alias struct {
+	Name       string
+	Definition string
+	Location   string
+}
+
+// Aliases returns the aliases defined in the config file, sorted by name.
+// A later definition of a name replaces an earlier one. Aliases shadowing a
+// command are ignored.
+func Aliases() ([]*Alias, error) {
+	path, err := FilePath()
+	if err != nil {
+		return nil, nil
+	}
+	file, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]*Alias)
+	var names []string
+	for _, entry := range file.entries {
+		if entry.section != aliasSection || IsCommand(entry.key) {
+			continue
+		}
+		if byName[entry.key] == nil {
+			names = append(names, entry.key)
+		}
+		byName[entry.key] = &Alias{Name: entry.key, Definition: entry.value, Location: file.location(entry)}
+	}
+
+	aliases := make([]*Alias, 0, len(names))
+	sort.Strings(names)
+	for _, name := range names {
+		aliases = append(aliases, byName[name])
+	}
+	return aliases, nil
+}
+
+// FindAlias returns the alias with the given name, or nil
+func FindAlias(name string) (*Alias, error) {
+	aliases, err := Aliases()
+	if err != nil {
+		return nil, err
+	}
+	for _, alias := range aliases {
+		if alias.Name == name {
+			return alias, nil
+		}
+	}
+	return nil, nil
+}
+
+// IsCommand checks if name is a built-in command, which aliases cannot replace
+func IsCommand(name string) bool {
+	return FindSubcommand(name) != nil || name == "history" || name == "completion"
+}
+
+// ExpandAlias returns the rgp invocations run by an alias, with args added
+// to the options of each. Aliases used in the definition are expanded too,
+// and options given later take precedence, so args override the alias.
+func ExpandAlias(alias *Alias, args []string) ([][]string, error) {
+	aliases, err := Aliases()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*Alias, len(aliases))
+	for _, a := range aliases {
+		byName[a.Name] = a
+	}
+	return expand(alias, args, byName, nil)
+}
+
+// expand expands an alias, tracking the aliases being expanded to detect cycles
+func expand(alias *Alias, args []string, aliases map[string]*Alias, expanding []string) ([][]string, error) {
+	for _, name := range expanding {
+		if name == alias.Name {
+			return nil, fmt.Errorf("alias '%s' refers to itself (%s)", alias.Name, strings.Join(append(expanding, alias.Name), " -> "))
+		}
+	}
+	expanding = append(expanding, alias.Name)
+
+	words, err := splitWords(alias.Definition)
+	if err != nil {
+		return nil, fmt.Errorf("alias '%s' (%s): %v", alias.Name, alias.Location, err)
+	}
+
+	var steps [][]string
+	for _, step := range splitSteps(words) {
+		if len(step) == 0 {
+			return nil, fmt.Errorf("alias '%s' (%s): empty command", alias.Name, alias.Location)
+		}
+
+		name := subcommandOf(step)
+		switch {
+		case aliases[name] != nil:
+			expanded, err := expand(aliases[name], append(step[1:len(step):len(step)], args...), aliases, expanding)
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, expanded...)
+		case name == "" || IsCommand(name):
+			steps = append(steps, passGitOptions(name, insertOptions(name, step, args)))
+		default:
+			return nil, fmt.Errorf("alias '%s' (%s): unknown command '%s'", alias.Name, alias.Location, name)
+		}
+	}
+	return steps, nil
+}
+
+// subcommandOf returns the command of an invocation, or "" for the flat interface
+func subcommandOf(words []string) string {
+	if len(words) == 0 || strings.HasPrefix(words[0], "-") {
+		return ""
+	}
+	return words[0]
+}
+
+// insertOptions adds options after the existing options of an invocation,
+// before any positional arguments, so they are parsed as flags
+func insertOptions(name string, words, options []string) []string {
+	if len(options) == 0 {
+		return words
+	}
+
+	start, fs := 0, FlagSet(name)
+	if name != "" {
+		start = 1
+	}
+	end := len(words)
+	if fs != nil {
+		end = start + flagsEnd(fs, words[start:])
+	}
+
+	result := make([]string, 0, len(words)+len(options))
+	result = append(result, words[:end]...)
+	result = append(result, options...)
+	return append(result, words[end:]...)
+}
+
+// passGitOptions moves the options of an invocation that start with -- and
+// are not rgp options to the git arguments, so "pull --ff-only" runs
+// "git pull --ff-only". Git options taking a value must be written as
+// --name=value.
+func passGitOptions(name string, words []string) []string {
+	sub := FindSubcommand(name)
+	if sub == nil || sub.command == nil {
+		return words
+	}
+
+	fs := FlagSet(name)
+	var options, gitOptions []string
+	rest := words[1:]
+	for ; len(rest) > 0; rest = rest[1:] {
+		arg := rest[0]
+		if arg == "--" || arg == "-" || !strings.HasPrefix(arg, "-") {
+			break
+		}
+		flagName, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		f := fs.Lookup(flagName)
+		switch {
+		case f == nil && strings.HasPrefix(arg, "--"):
+			gitOptions = append(gitOptions, arg)
+		case f != nil && !hasValue && !isBoolFlag(f) && len(rest) > 1:
+			options = append(options, arg, rest[1])
+			rest = rest[1:]
+		default:
+			options = append(options, arg)
+		}
+	}
+	if len(gitOptions) == 0 {
+		return words
+	}
+	if len(rest) > 0 && rest[0] == "--" {
+		rest = rest[1:]
+	}
+
+	result := make([]string, 0, len(words)+1)
+	result = append(result, name)
+	result = append(result, options...)
+	result = append(result, "--")
+	result = append(result, gitOptions...)
+	return append(result, rest...)
+}
+
+// flagsEnd returns the index of the first argument that is not a flag or a
+// flag value, like flag.FlagSet.Parse would stop at
+func flagsEnd(fs *flag.FlagSet, args []string) int {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || arg == "-" || !strings.HasPrefix(arg, "-") {
+			return i
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if f := fs.Lookup(name); f != nil && !hasValue && !isBoolFlag(f) {
+			i++
+		}
+	}
+	return len(args)
+}
+
+// isBoolFlag checks if the flag can be given without a value
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// splitSteps splits the words of an alias at each &&
+func splitSteps(words []string) [][]string {
+	steps := [][]string{nil}
+	for _, word := range words {
+		if word == stepSeparator {
+			steps = append(steps, nil)
+			continue
+		}
+		steps[len(steps)-1] = append(steps[len(steps)-1], word)
+	}
+	return steps
+}
+
+// splitWords splits a definition into words like a shell would: words are
+// separated by spaces, and quotes or a backslash keep spaces in a word
+func splitWords(text string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+				word.WriteRune(runes[i])
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\' && i+1 < len(runes):
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testAliases is the config file used by the alias tests
+const testAliases = `
+[alias]
+sync = run -workers 16 -- fetch --all --prune
+morning = pull -autostash -include 'api-*,web-*' && sync && status -quiet -- --short
+update = pull --ff-only -autostash
+loop = run -- fetch && again
+again = loop -quiet
+self = self
+broken = frobnicate --all
+pull = fetch
+`
+
+func TestExpandAlias(t *testing.T) {
+	useConfigFile(t, testAliases)
+
+	tests := []struct {
+		alias string
+		args  []string
+		want  [][]string
+	}{
+		{
+			alias: "sync",
+			want:  [][]string{{"run", "-workers", "16", "--", "fetch", "--all", "--prune"}},
+		},
+		{
+			alias: "sync",
+			args:  []string{"-path", "/work", "-workers", "4"},
+			want:  [][]string{{"run", "-workers", "16", "-path", "/work", "-workers", "4", "--", "fetch", "--all", "--prune"}},
+		},
+		{
+			alias: "update",
+			want:  [][]string{{"pull", "-autostash", "--", "--ff-only"}},
+		},
+		{
+			alias: "morning",
+			args:  []string{"-quiet"},
+			want: [][]string{
+				{"pull", "-autostash", "-include", "api-*,web-*", "-quiet"},
+				{"run", "-workers", "16", "-quiet", "--", "fetch", "--all", "--prune"},
+				{"status", "-quiet", "-quiet", "--", "--short"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alias+" "+strings.Join(tt.args, " "), func(t *testing.T) {
+			alias, err := FindAlias(tt.alias)
+			if err != nil || alias == nil {
+				t.Fatalf("FindAlias(%q) = %v, %v", tt.alias, alias, err)
+			}
+			steps, err := ExpandAlias(alias, tt.args)
+			if err != nil {
+				t.Fatalf("ExpandAlias(%q): %v", tt.alias, err)
+			}
+			if !reflect.DeepEqual(steps, tt.want) {
+				t.Errorf("ExpandAlias(%q, %q) = %q, want %q", tt.alias, tt.args, steps, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandAliasErrors(t *testing.T) {
+	useConfigFile(t, testAliases)
+
+	tests := []struct {
+		alias string
+		err   string
+	}{
+		{"loop", "alias 'loop' refers to itself (loop -> again -> loop)"},
+		{"self", "alias 'self' refers to itself (self -> self)"},
+		{"broken", "unknown command 'frobnicate'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alias, func(t *testing.T) {
+			alias, err := FindAlias(tt.alias)
+			if err != nil || alias == nil {
+				t.Fatalf("FindAlias(%q) = %v, %v", tt.alias, alias, err)
+			}
+			_, err = ExpandAlias(alias, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ExpandAlias(%q) error = %v, want %q", tt.alias, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestAliasesIgnoreCommands(t *testing.T) {
+	useConfigFile(t, testAliases)
+
+	if alias, _ := FindAlias("pull"); alias != nil {
+		t.Errorf("FindAlias(pull) = %+v, want nil for a command name", alias)
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    []string
+		wantErr bool
+	}{
+		{text: "run -- fetch  --all", want: []string{"run", "--", "fetch", "--all"}},
+		{text: `pull -include 'api-*, web-*'`, want: []string{"pull", "-include", "api-*, web-*"}},
+		{text: `status -- "--format=%h \"%s\""`, want: []string{"status", "--", `--format=%h "%s"`}},
+		{text: `run -- commit -m a\ b`, want: []string{"run", "--", "commit", "-m", "a b"}},
+		{text: `run -- log ''`, want: []string{"run", "--", "log", ""}},
+		{text: `pull -include 'api-*`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			words, err := splitWords(tt.text)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("splitWords(%q) = %q, want an error", tt.text, words)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("splitWords(%q): %v", tt.text, err)
+			}
+			if !reflect.DeepEqual(words, tt.want) {
+				t.Errorf("splitWords(%q) = %q, want %q", tt.text, words, tt.want)
+			}
+		})
+	}
+}
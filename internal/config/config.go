@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,100 +14,505 @@ import (
 
 // ParseFlags parses command line flags and returns configuration
 func ParseFlags() *types.Config {
-	config := &types.Config{}
-
-	flag.StringVar(&config.RootPath, "path", ".", "Root path to search for Git repositories")
-	flag.StringVar(&config.Command, "command", "pull", "Git command to execute")
-	flag.BoolVar(&config.Parallel, "parallel", true, "Execute commands in parallel")
-	flag.IntVar(&config.MaxWorkers, "workers", 4, "Maximum number of parallel workers")
-	
-	var timeoutStr string
-	flag.StringVar(&timeoutStr, "timeout", "30s", "Timeout for each command")
-	
-	flag.BoolVar(&config.IgnoreDirty, "ignore-dirty", false, "Ignore repositories with uncommitted changes")
-	
-	var includeStr, excludeStr string
-	flag.StringVar(&includeStr, "include", "", "Comma-separated patterns to include repositories")
-	flag.StringVar(&excludeStr, "exclude", "", "Comma-separated patterns to exclude repositories")
-	
-	flag.BoolVar(&config.Verbose, "verbose", false, "Verbose output")
-	flag.BoolVar(&config.AllBranches, "all-branches", false, "Pull all branches (only works with pull command)")
-	flag.BoolVar(&config.NoColor, "no-color", false, "Disable colored output")
-
-	var help bool
-	flag.BoolVar(&help, "help", false, "Show help")
-	flag.BoolVar(&help, "h", false, "Show help")
-
-	flag.Parse()
-
-	if help {
-		showHelp()
+	return ParseArgs(os.Args[1:])
+}
+
+// ParseArgs parses the given arguments as command line flags and returns configuration.
+// This is the flat interface where the git command is given with -command.
+func ParseArgs(args []string) *types.Config {
+	return parse(legacyCommand, args)
+}
+
+// flagGroup selects which flags a subcommand accepts
+type flagGroup int
+
+const (
+	discoveryFlags flagGroup = 1 << iota
+	executionFlags
+	pullFlags
+	commandFlags
+	displayFlags
+	listFlags
+)
+
+// flagValues holds raw flag values that are parsed after the command line
+type flagValues struct {
+	hostLimits  string
+	timeout     string
+	repoTimeout string
+	runTimeout  string
+	dirtyStates string
+	include     string
+	exclude     string
+	exitCodes   string
+	columns     string
+	explain     explainFormat
+	help        bool
+}
+
+// Default returns the configuration used when no flag is given
+func Default() *types.Config {
+	return &types.Config{
+		RootPath:     ".",
+		Command:      "pull",
+		Parallel:     true,
+		MaxWorkers:   4,
+		Timeout:      30 * time.Second,
+		DirtyStates:  append([]string(nil), types.AllDirtyStates...),
+		PullStrategy: types.PullStrategyFFOnly,
+		OutputMode:   types.OutputSummary,
+		ExitCodes:    types.DefaultExitCodes,
+		ListFormat:   types.ListPlain,
+		ListColumns:  []string{types.ColumnPath},
+		Theme:        "default",
+		Icons:        "auto",
+	}
+}
+
+// registerFlags defines the flags of the given groups on fs
+func registerFlags(fs *flag.FlagSet, groups flagGroup, config *types.Config, values *flagValues) {
+	if groups&discoveryFlags != 0 {
+		fs.StringVar(&config.RootPath, "path", config.RootPath, "Root path to search for Git repositories")
+		fs.StringVar(&values.include, "include", values.include, "Comma-separated patterns to include repositories")
+		fs.StringVar(&values.exclude, "exclude", values.exclude, "Comma-separated patterns to exclude repositories")
+	}
+
+	if groups&commandFlags != 0 {
+		fs.StringVar(&config.Command, "command", config.Command, "Git command to execute")
+		fs.BoolVar(&config.OnlyFailed, "only-failed", config.OnlyFailed, "Re-run the last recorded command only on the repositories that failed")
+	}
+
+	if groups&executionFlags != 0 {
+		fs.BoolVar(&config.Parallel, "parallel", config.Parallel, "Execute commands in parallel")
+		fs.IntVar(&config.MaxWorkers, "workers", config.MaxWorkers, "Maximum number of parallel workers")
+		fs.StringVar(&values.hostLimits, "host-limits", values.hostLimits, "Comma-separated per-host concurrency limits for network commands (e.g. 'git.example.com=2,*.corp=4')")
+
+		fs.StringVar(&values.timeout, "timeout", values.timeout, "Timeout for each command")
+		fs.StringVar(&values.repoTimeout, "repo-timeout", values.repoTimeout, "Deadline for all steps in a single repository (0 = no limit)")
+		fs.StringVar(&values.runTimeout, "run-timeout", values.runTimeout, "Deadline for the entire run (0 = no limit)")
+
+		fs.BoolVar(&config.IgnoreDirty, "ignore-dirty", config.IgnoreDirty, "Ignore repositories with uncommitted changes")
+		fs.StringVar(&values.dirtyStates, "dirty-states", values.dirtyStates, "Comma-separated states that count as dirty (staged, unstaged, untracked, rebase)")
+
+		fs.BoolVar(&config.FailFast, "fail-fast", config.FailFast, "Stop processing queued repositories after the first failure")
+		fs.IntVar(&config.MaxFailures, "max-failures", config.MaxFailures, "Stop processing queued repositories after this many failures (0 = unlimited)")
+
+		fs.StringVar(&config.JUnitReport, "junit-report", config.JUnitReport, "Write a JUnit XML report to this file")
+		fs.StringVar(&config.MarkdownReport, "markdown-report", config.MarkdownReport, "Write a Markdown report to this file")
+		fs.StringVar(&config.HTMLReport, "html-report", config.HTMLReport, "Write a self-contained HTML report to this file")
+
+		fs.StringVar(&config.LogDir, "log-dir", config.LogDir, "Write the full output of each repository to a file in a new subdirectory of this directory for each run")
+		fs.StringVar(&config.HistoryFile, "history-file", config.HistoryFile, "File where run history is recorded (default: $XDG_DATA_HOME/rgp/history.jsonl)")
+		fs.BoolVar(&config.NoHistory, "no-history", config.NoHistory, "Do not record this run in the history")
+
+		fs.StringVar(&config.OutputMode, "output", config.OutputMode, "Output mode: summary, grouped (each repository's output as a block), prefix (each line prefixed with the repository name) or json (a JSON line per event)")
+		fs.BoolVar(&config.SkipEmpty, "skip-empty", config.SkipEmpty, "Skip repositories with empty output in grouped mode")
+		fs.BoolVar(&config.Quiet, "quiet", config.Quiet, "Only print failures")
+		fs.StringVar(&values.exitCodes, "exit-codes", values.exitCodes, "Comma-separated exit code overrides: failed, skipped, timeout, none (e.g. 'skipped=0,none=5')")
+		fs.BoolVar(&config.Verbose, "verbose", config.Verbose, "Verbose output")
+		fs.BoolVar(&config.Interactive, "interactive", config.Interactive, "Pick the repositories to run on from a filterable checklist")
+		fs.BoolVar(&config.TUI, "tui", config.TUI, "Follow the run in a full-screen view where repositories can be inspected, cancelled and retried")
+	}
+
+	if groups&pullFlags != 0 {
+		fs.BoolVar(&config.Autostash, "autostash", config.Autostash, "Stash uncommitted changes before pull and restore them afterwards")
+		fs.BoolVar(&config.StashUntracked, "stash-untracked", config.StashUntracked, "Include untracked files when autostashing")
+		fs.StringVar(&config.PullStrategy, "pull-strategy", config.PullStrategy, "Pull strategy used for every pull with -autostash (rebase or ff-only)")
+		fs.BoolVar(&config.AllBranches, "all-branches", config.AllBranches, "Pull all branches (only works with pull command)")
+	}
+
+	if groups&listFlags != 0 {
+		fs.StringVar(&config.ListFormat, "format", config.ListFormat, "List format: plain (one repository per line), nul (NUL-separated, for xargs -0) or json")
+		fs.StringVar(&values.columns, "columns", values.columns, "Comma-separated columns to print: path, name, relative, branch, remote, kind")
+	}
+
+	if groups&displayFlags != 0 {
+		fs.StringVar(&config.Profile, "profile", config.Profile, "Apply the settings of a [profile NAME] section of the config file")
+		fs.BoolVar(&config.NoColor, "no-color", config.NoColor, "Disable colored output")
+		fs.StringVar(&config.Theme, "theme", config.Theme, "Color theme: default, 256, truecolor or colorblind")
+		fs.StringVar(&config.Icons, "icons", config.Icons, "Icon set: auto (ASCII unless the locale is UTF-8), unicode or ascii")
+		fs.Var(&values.explain, "explain", "Print the resolved configuration and where each value comes from instead of running (-explain=json for JSON)")
+		fs.BoolVar(&values.help, "help", false, "Show help")
+		fs.BoolVar(&values.help, "h", false, "Show help")
+	}
+}
+
+// parse parses the arguments of a subcommand, validates them and returns configuration
+func parse(sub *Subcommand, args []string) *types.Config {
+	config := Default()
+	if sub.defaults != nil {
+		sub.defaults(config)
+	}
+	values := &flagValues{
+		timeout:     config.Timeout.String(),
+		repoTimeout: config.RepoTimeout.String(),
+		runTimeout:  config.RunTimeout.String(),
+		dirtyStates: strings.Join(config.DirtyStates, ","),
+		columns:     strings.Join(config.ListColumns, ","),
+	}
+
+	fs := flag.NewFlagSet(sub.Name, flag.ExitOnError)
+	registerFlags(fs, sub.flags, config, values)
+	fs.Usage = func() { showUsage(sub, fs) }
+	fs.Parse(args)
+
+	if values.help {
+		showUsage(sub, fs)
 		os.Exit(0)
 	}
 
+	// Flags given on the command line take precedence over the environment,
+	// which takes precedence over the profile and then the rest of the
+	// config file
+	sources := make(map[string]source)
+	fs.Visit(func(f *flag.Flag) {
+		sources[f.Name] = source{kind: sourceFlag, location: "-" + f.Name}
+	})
+
+	// The profile selects the settings read from the config file, so it
+	// comes from the command line or the environment only
+	if name := EnvName("profile"); sources["profile"].kind != sourceFlag && os.Getenv(name) != "" {
+		config.Profile = os.Getenv(name)
+		sources["profile"] = source{kind: sourceEnv, location: name}
+	}
+	file, warnings := applyConfigFile(fs, sources, config.Profile)
+	warnings = append(warnings, applyEnvironment(fs, sources)...)
+
+	// Build the command from the positional arguments
+	if sub.command != nil {
+		command, err := sub.command(fs.Args())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(err.Error()))
+			os.Exit(1)
+		}
+		config.Command = command
+		sources["command"] = source{kind: sourceArgs}
+	}
+
+	// Validate theme and icons first so errors below use them
+	if err := colors.SetTheme(config.Theme); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid theme: %v (expected default, 256, truecolor or colorblind)%s", err, origin(sources, "theme"))))
+		os.Exit(1)
+	}
+	if err := colors.SetIcons(config.Icons); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid icons: %v (expected auto, unicode or ascii)%s", err, origin(sources, "icons"))))
+		os.Exit(1)
+	}
+
 	// Validate root path
 	if info, err := os.Stat(config.RootPath); err != nil {
-		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid path '%s': %v", config.RootPath, err)))
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid path '%s': %v%s", config.RootPath, err, origin(sources, "path"))))
 		os.Exit(1)
 	} else if !info.IsDir() {
-		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Path '%s' is not a directory", config.RootPath)))
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Path '%s' is not a directory%s", config.RootPath, origin(sources, "path"))))
 		os.Exit(1)
 	}
 
 	// Validate command
 	if config.Command == "" {
-		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error("Git command cannot be empty"))
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error("Git command cannot be empty"+origin(sources, "command")))
 		os.Exit(1)
 	}
 
 	// Validate max workers
 	if config.MaxWorkers <= 0 {
-		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error("Number of workers must be positive"))
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error("Number of workers must be positive"+origin(sources, "workers")))
+		os.Exit(1)
+	}
+
+	// Validate max failures
+	if config.MaxFailures < 0 {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error("Maximum number of failures cannot be negative"+origin(sources, "max-failures")))
+		os.Exit(1)
+	}
+
+	// Quiet mode, JSON events and the full-screen view take precedence over verbose output
+	if config.Quiet || config.TUI || config.OutputMode == types.OutputJSON {
+		config.Verbose = false
+	}
+
+	// Validate output mode
+	if config.OutputMode != types.OutputSummary && config.OutputMode != types.OutputGrouped && config.OutputMode != types.OutputPrefix && config.OutputMode != types.OutputJSON {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid output mode '%s' (expected summary, grouped, prefix or json)%s", config.OutputMode, origin(sources, "output"))))
+		os.Exit(1)
+	}
+
+	// The full-screen view replaces streamed output
+	if config.TUI && (config.OutputMode == types.OutputPrefix || config.OutputMode == types.OutputJSON) {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("-tui cannot be combined with -output %s", config.OutputMode)))
+		os.Exit(1)
+	}
+
+	// Validate pull strategy
+	if config.PullStrategy != types.PullStrategyRebase && config.PullStrategy != types.PullStrategyFFOnly {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid pull strategy '%s' (expected rebase or ff-only)%s", config.PullStrategy, origin(sources, "pull-strategy"))))
+		os.Exit(1)
+	}
+
+	// Validate list format
+	if config.ListFormat != types.ListPlain && config.ListFormat != types.ListNUL && config.ListFormat != types.ListJSON {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid list format '%s' (expected plain, nul or json)%s", config.ListFormat, origin(sources, "format"))))
 		os.Exit(1)
 	}
 
+	// Parse list columns
+	config.ListColumns = nil
+	for _, column := range strings.Split(values.columns, ",") {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			continue
+		}
+		if !isValidColumn(column) {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid column '%s' (expected one of: %s)%s", column, strings.Join(types.AllListColumns, ", "), origin(sources, "columns"))))
+			os.Exit(1)
+		}
+		config.ListColumns = append(config.ListColumns, column)
+	}
+
+	// Parse host limits
+	if values.hostLimits != "" {
+		hostLimits, err := parseHostLimits(values.hostLimits)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid host limits: %v%s", err, origin(sources, "host-limits"))))
+			os.Exit(1)
+		}
+		config.HostLimits = hostLimits
+	}
+
+	// Parse exit codes
+	if exitCodes, err := parseExitCodes(values.exitCodes); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid exit codes: %v%s", err, origin(sources, "exit-codes"))))
+		os.Exit(1)
+	} else {
+		config.ExitCodes = exitCodes
+	}
+
 	// Parse timeout
-	if timeout, err := time.ParseDuration(timeoutStr); err != nil {
-		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid timeout format: %v", err)))
+	if timeout, err := time.ParseDuration(values.timeout); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid timeout format: %v%s", err, origin(sources, "timeout"))))
 		os.Exit(1)
 	} else {
 		config.Timeout = timeout
 	}
 
+	// Parse dirty states
+	config.DirtyStates = nil
+	for _, state := range strings.Split(values.dirtyStates, ",") {
+		state = strings.TrimSpace(state)
+		if state == "" {
+			continue
+		}
+		if !isValidDirtyState(state) {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid dirty state '%s' (expected one of: %s)%s", state, strings.Join(types.AllDirtyStates, ", "), origin(sources, "dirty-states"))))
+			os.Exit(1)
+		}
+		config.DirtyStates = append(config.DirtyStates, state)
+	}
+
+	// Parse repository and run deadlines
+	if repoTimeout, err := time.ParseDuration(values.repoTimeout); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid repository timeout format: %v%s", err, origin(sources, "repo-timeout"))))
+		os.Exit(1)
+	} else {
+		config.RepoTimeout = repoTimeout
+	}
+
+	if runTimeout, err := time.ParseDuration(values.runTimeout); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid run timeout format: %v%s", err, origin(sources, "run-timeout"))))
+		os.Exit(1)
+	} else {
+		config.RunTimeout = runTimeout
+	}
+
 	// Parse include/exclude patterns
-	if includeStr != "" {
-		config.IncludePatterns = strings.Split(includeStr, ",")
+	if values.include != "" {
+		config.IncludePatterns = strings.Split(values.include, ",")
 		for i, pattern := range config.IncludePatterns {
 			config.IncludePatterns[i] = strings.TrimSpace(pattern)
 		}
 	}
 
-	if excludeStr != "" {
-		config.ExcludePatterns = strings.Split(excludeStr, ",")
+	if values.exclude != "" {
+		config.ExcludePatterns = strings.Split(values.exclude, ",")
 		for i, pattern := range config.ExcludePatterns {
 			config.ExcludePatterns[i] = strings.TrimSpace(pattern)
 		}
 	}
 
+	if values.explain != "" {
+		explain(sub, fs, config, sources, file, warnings)
+		os.Exit(0)
+	}
+
+	// Problems in the config file do not stop the run
+	if !config.Quiet {
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.WarningIcon(), colors.Warning(warning))
+		}
+	}
+
 	return config
 }
 
-func showHelp() {
+// parseHostLimits parses host limits in the form "pattern=N,pattern=N"
+func parseHostLimits(value string) (map[string]int, error) {
+	hostLimits := make(map[string]int)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		pattern, limitStr, found := strings.Cut(entry, "=")
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if !found || pattern == "" {
+			return nil, fmt.Errorf("expected pattern=limit, got '%s'", entry)
+		}
+
+		limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("limit for '%s' must be a positive number", pattern)
+		}
+		hostLimits[pattern] = limit
+	}
+	return hostLimits, nil
+}
+
+// parseExitCodes applies overrides in the form "outcome=code,outcome=code" to the default exit codes
+func parseExitCodes(value string) (types.ExitCodes, error) {
+	exitCodes := types.DefaultExitCodes
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		outcome, codeStr, found := strings.Cut(entry, "=")
+		if !found {
+			return exitCodes, fmt.Errorf("expected outcome=code, got '%s'", entry)
+		}
+
+		code, err := strconv.Atoi(strings.TrimSpace(codeStr))
+		if err != nil || code < 0 || code > 125 {
+			return exitCodes, fmt.Errorf("exit code for '%s' must be between 0 and 125", outcome)
+		}
+
+		switch strings.TrimSpace(outcome) {
+		case "failed":
+			exitCodes.Failed = code
+		case "skipped":
+			exitCodes.Skipped = code
+		case "timeout":
+			exitCodes.TimedOut = code
+		case "none":
+			exitCodes.NothingFound = code
+		default:
+			return exitCodes, fmt.Errorf("unknown outcome '%s' (expected failed, skipped, timeout or none)", outcome)
+		}
+	}
+	return exitCodes, nil
+}
+
+// isValidDirtyState checks if state is a supported dirty state
+func isValidDirtyState(state string) bool {
+	for _, valid := range types.AllDirtyStates {
+		if state == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidColumn checks if column is a supported list column
+func isValidColumn(column string) bool {
+	for _, valid := range types.AllListColumns {
+		if column == valid {
+			return true
+		}
+	}
+	return false
+}
+
+func showHelp(fs *flag.FlagSet) {
 	fmt.Println("Recursive Git Pull - Execute Git commands recursively on multiple repositories")
 	fmt.Println("")
 	fmt.Println("Usage:")
 	fmt.Println("  rgp [options]")
+	fmt.Println("  rgp <command> [options] [args]")
+	fmt.Println("")
+	fmt.Println("Commands:")
+	for _, sub := range Subcommands {
+		fmt.Printf("  %-14s %s\n", sub.Name, sub.Description)
+	}
+	fmt.Printf("  %-14s %s\n", "history", "List recorded runs, or compare two of them with 'history diff'")
+	fmt.Printf("  %-14s %s\n", "completion", "Print the shell completion script for bash, zsh or fish")
+	if aliases, err := Aliases(); err == nil && len(aliases) > 0 {
+		fmt.Println("")
+		fmt.Println("Aliases (from the config file):")
+		for _, alias := range aliases {
+			fmt.Printf("  %-14s %s\n", alias.Name, alias.Definition)
+		}
+	}
+	fmt.Println("")
+	fmt.Println("Run 'rgp <command> -help' for the options of a command.")
+	fmt.Println("Without a command, rgp runs the git command given with -command.")
 	fmt.Println("")
 	fmt.Println("Options:")
-	flag.PrintDefaults()
+	fs.PrintDefaults()
 	fmt.Println("")
 	fmt.Println("Examples:")
+	fmt.Println("  rgp pull -autostash -- --prune")
+	fmt.Println("  rgp status -- --short")
+	fmt.Println("  rgp run log --oneline -3")
+	fmt.Println("  rgp exec -- make test")
+	fmt.Println("  rgp pull -interactive")
+	fmt.Println("  rgp pull -tui -workers 16")
+	fmt.Println("  rgp list -include '*-service' -format nul | xargs -0 -n1 du -sh")
+	fmt.Println("  rgp list -columns relative,branch,remote -format json")
+	fmt.Println("  source <(rgp completion bash)")
 	fmt.Println("  rgp -path ./workspace -command pull")
 	fmt.Println("  rgp -path ./projects -command status -parallel=false")
 	fmt.Println("  rgp -path ./repos -command pull -all-branches")
 	fmt.Println("  rgp -include '*-service' -exclude 'test-*'")
+	fmt.Println("  rgp -command pull -autostash -stash-untracked -pull-strategy rebase")
+	fmt.Println("  rgp -command 'checkout main' -ignore-dirty -dirty-states staged,unstaged")
+	fmt.Println("  rgp -command fetch -max-failures 3")
+	fmt.Println("  rgp -command fetch -workers 16 -host-limits 'git.example.com=2'")
+	fmt.Println("  rgp -command pull -all-branches -repo-timeout 2m -run-timeout 30m")
+	fmt.Println("  rgp -command fetch -junit-report rgp-results.xml")
+	fmt.Println("  rgp -command 'status --short' -markdown-report hygiene.md -html-report hygiene.html")
+	fmt.Println("  rgp -command pull -log-dir ./rgp-logs")
+	fmt.Println("  rgp -command 'log --oneline -5' -output grouped -skip-empty")
+	fmt.Println("  rgp -command 'grep -n TODO' -output prefix | sort")
+	fmt.Println("  rgp -command fetch -quiet -exit-codes skipped=0")
 	fmt.Println("  rgp -no-color -verbose  # Disable colors for scripting")
+	fmt.Println("  rgp -theme colorblind -icons ascii")
+	fmt.Println("  rgp pull -workers 8 -explain")
+	fmt.Println("  rgp fetch -profile ci")
+	fmt.Println("")
+	fmt.Println("Config file:")
+	fmt.Println("  Options are read from $XDG_CONFIG_HOME/rgp/config (~/.config/rgp/config),")
+	fmt.Println("  one 'option = value' per line using the flag names, e.g. 'workers = 8'.")
+	fmt.Println("  A [profile NAME] section holds settings applied on top of the others with")
+	fmt.Println("  -profile NAME or RGP_PROFILE=NAME.")
+	fmt.Println("  RGP_ environment variables take precedence over the file, and flags over")
+	fmt.Println("  both. Use -explain to see where each value comes from.")
+	fmt.Println("  An [alias] section defines commands made of rgp invocations, run in order")
+	fmt.Println("  with &&, e.g. 'morning = run -- fetch --all --prune && status -- -sb'.")
+	fmt.Println("  Options given to an alias are added to each invocation.")
+	fmt.Println("")
+	fmt.Println("Exit codes (defaults, configurable with -exit-codes):")
+	fmt.Println("  0  All repositories succeeded, or no repositories were found (none)")
+	fmt.Println("  1  Some repositories failed (failed)")
+	fmt.Println("  3  Some repositories were skipped (skipped)")
+	fmt.Println("  4  All repositories timed out (timeout)")
 	fmt.Println("")
 	fmt.Println("Environment variables:")
-	fmt.Println("  NO_COLOR  Set to any value to disable colors")
+	fmt.Println("  NO_COLOR        Set to any value to disable colors")
+	fmt.Println("  FORCE_COLOR     Set to any value but 0 to keep colors when output is not a terminal")
+	fmt.Println("  CLICOLOR_FORCE  Same as FORCE_COLOR")
+	fmt.Println("  RGP_<OPTION>    Set any option, named after its flag in upper case with - as _")
+	fmt.Println("                  (e.g. RGP_WORKERS=8, RGP_TIMEOUT=1m, RGP_DIRTY_STATES=staged)")
+	fmt.Println("  RGP_CONFIG      Path of the config file to read instead of the default")
+	fmt.Println("  LC_ALL, LC_CTYPE, LANG  ASCII icons are used unless the locale is UTF-8 (see -icons)")
 }
\ No newline at end of file
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
+)
+
+// envPrefix starts the environment variables setting options
+const envPrefix = "RGP_"
+
+// envOnlyVariables are RGP_ variables that do not set a flag
+var envOnlyVariables = map[string]bool{"RGP_CONFIG": true}
+
+// EnvName returns the environment variable setting a flag, e.g. RGP_DIRTY_STATES
+// for -dirty-states
+func EnvName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnvironment sets the flags of fs from RGP_ environment variables,
+// except flags already given on the command line, and records their
+// sources. Empty variables are ignored. It returns warnings about variables
+// that match no flag.
+func applyEnvironment(fs *flag.FlagSet, sources map[string]source) []string {
+	known := make(map[string]bool)
+	allFlags().VisitAll(func(f *flag.Flag) {
+		if !cliOnlyFlags[f.Name] {
+			known[EnvName(f.Name)] = true
+		}
+	})
+
+	var warnings []string
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		if strings.HasPrefix(name, envPrefix) && !known[name] && !envOnlyVariables[name] {
+			warnings = append(warnings, fmt.Sprintf("Unknown environment variable %s", name))
+		}
+	}
+	sort.Strings(warnings)
+
+	fs.VisitAll(func(f *flag.Flag) {
+		name := EnvName(f.Name)
+		value := os.Getenv(name)
+		if value == "" || !known[name] || sources[f.Name].kind == sourceFlag {
+			return
+		}
+		if err := fs.Set(f.Name, value); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid value '%s' for %s: %v", value, name, err)))
+			os.Exit(1)
+		}
+		sources[f.Name] = source{kind: sourceEnv, location: name}
+	})
+	return warnings
+}
+
+// origin describes where a flag's value came from for error messages,
+// e.g. " (from RGP_TIMEOUT)". Values given as flags need no explanation.
+func origin(sources map[string]source, flagName string) string {
+	switch src := sources[flagName]; src.kind {
+	case sourceFile, sourceEnv:
+		return fmt.Sprintf(" (from %s)", src.location)
+	}
+	return ""
+}
@@ -0,0 +1,312 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// Kinds of source a setting can come from, in increasing precedence
+const (
+	sourceDefault = "default"
+	sourceCommand = "command"
+	sourceFile    = "file"
+	sourceEnv     = "env"
+	sourceFlag    = "flag"
+	sourceArgs    = "args"
+)
+
+// source records where the value of a flag came from
+type source struct {
+	kind     string
+	location string
+}
+
+func (s source) String() string {
+	switch s.kind {
+	case sourceDefault:
+		return "default"
+	case sourceArgs:
+		return "arguments"
+	case sourceEnv:
+		return "env " + s.location
+	case sourceFlag:
+		return "flag " + s.location
+	}
+	return s.location
+}
+
+// Explain formats accepted by -explain
+const (
+	explainText = "text"
+	explainJSON = "json"
+)
+
+// explainFormat is the value of -explain. It can be given without a value
+// for text output.
+type explainFormat string
+
+func (f *explainFormat) String() string {
+	return string(*f)
+}
+
+func (f *explainFormat) Set(value string) error {
+	switch value {
+	case "true", explainText:
+		*f = explainText
+	case explainJSON:
+		*f = explainJSON
+	case "false":
+		*f = ""
+	default:
+		return fmt.Errorf("expected text or json")
+	}
+	return nil
+}
+
+func (f *explainFormat) IsBoolFlag() bool {
+	return true
+}
+
+// explainFields maps the fields of types.Config to the flags setting them,
+// in the order -explain prints them
+var explainFields = []struct {
+	field string
+	flag  string
+}{
+	{"Profile", "profile"},
+	{"RootPath", "path"},
+	{"Command", "command"},
+	{"IncludePatterns", "include"},
+	{"ExcludePatterns", "exclude"},
+	{"OnlyFailed", "only-failed"},
+	{"Parallel", "parallel"},
+	{"MaxWorkers", "workers"},
+	{"HostLimits", "host-limits"},
+	{"Timeout", "timeout"},
+	{"RepoTimeout", "repo-timeout"},
+	{"RunTimeout", "run-timeout"},
+	{"IgnoreDirty", "ignore-dirty"},
+	{"DirtyStates", "dirty-states"},
+	{"Autostash", "autostash"},
+	{"StashUntracked", "stash-untracked"},
+	{"PullStrategy", "pull-strategy"},
+	{"AllBranches", "all-branches"},
+	{"FailFast", "fail-fast"},
+	{"MaxFailures", "max-failures"},
+	{"JUnitReport", "junit-report"},
+	{"MarkdownReport", "markdown-report"},
+	{"HTMLReport", "html-report"},
+	{"LogDir", "log-dir"},
+	{"HistoryFile", "history-file"},
+	{"NoHistory", "no-history"},
+	{"OutputMode", "output"},
+	{"SkipEmpty", "skip-empty"},
+	{"Quiet", "quiet"},
+	{"Verbose", "verbose"},
+	{"ExitCodes", "exit-codes"},
+	{"Interactive", "interactive"},
+	{"TUI", "tui"},
+	{"ListFormat", "format"},
+	{"ListColumns", "columns"},
+	{"NoColor", "no-color"},
+	{"Theme", "theme"},
+	{"Icons", "icons"},
+}
+
+// explainSetting is a setting printed by -explain
+type explainSetting struct {
+	Field    string `json:"field"`
+	Flag     string `json:"flag"`
+	Value    any    `json:"value"`
+	Source   string `json:"source"`
+	Location string `json:"location,omitempty"`
+}
+
+// explainOutput is the document printed by -explain json
+type explainOutput struct {
+	Command    string           `json:"command"`
+	ConfigFile string           `json:"config_file"`
+	FileFound  bool             `json:"config_file_found"`
+	Settings   []explainSetting `json:"settings"`
+	Warnings   []string         `json:"warnings"`
+}
+
+// explain prints the resolved configuration of a subcommand with the source
+// of each value, and the warnings about it
+func explain(sub *Subcommand, fs *flag.FlagSet, config *types.Config, sources map[string]source, file *configFile, warnings []string) {
+	current := reflect.ValueOf(config).Elem()
+	initial := reflect.ValueOf(Default()).Elem()
+
+	doc := explainOutput{Command: sub.Name, Warnings: append(warnings, conflicts(fs, config, sources)...)}
+	if file != nil {
+		doc.ConfigFile = file.path
+		doc.FileFound = file.found
+	}
+	for _, f := range explainFields {
+		value := current.FieldByName(f.field)
+		src, ok := sources[f.flag]
+		if !ok {
+			src = source{kind: sourceDefault}
+		}
+
+		// Settings without a flag in this command are only shown when the
+		// command itself changes them
+		if fs.Lookup(f.flag) == nil && src.kind != sourceArgs {
+			if reflect.DeepEqual(value.Interface(), initial.FieldByName(f.field).Interface()) {
+				continue
+			}
+			src = source{kind: sourceCommand, location: sub.Name}
+		}
+
+		setting := explainSetting{Field: f.field, Flag: f.flag, Value: jsonValue(value), Source: src.kind}
+		if src.kind != sourceDefault && src.kind != sourceArgs {
+			setting.Location = src.location
+		}
+		doc.Settings = append(doc.Settings, setting)
+	}
+
+	if fs.Lookup("explain").Value.String() == explainJSON {
+		if doc.Warnings == nil {
+			doc.Warnings = []string{}
+		}
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(err.Error()))
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	if config.NoColor {
+		colors.SetForceNoColor(true)
+	}
+	fmt.Printf("%s %s\n", colors.Info("Configuration of:"), colors.Bold(sub.Name))
+	if file != nil {
+		status := ""
+		if !file.found {
+			status = colors.Dim(" (not found)")
+		}
+		fmt.Printf("%s %s%s\n", colors.Info("Config file:"), file.path, status)
+	}
+	fmt.Println("")
+
+	// Only the last column is styled so escape codes do not break alignment
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, setting := range doc.Settings {
+		from := colors.Info(sources[setting.Flag].String())
+		switch setting.Source {
+		case sourceDefault:
+			from = colors.Dim("default")
+		case sourceCommand:
+			from = colors.Dim("set by the " + setting.Location + " command")
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", setting.Field, textValue(current.FieldByName(setting.Field)), from)
+	}
+	w.Flush()
+
+	if len(doc.Warnings) > 0 {
+		fmt.Println("")
+		fmt.Println(colors.Warning("Warnings:"))
+		for _, warning := range doc.Warnings {
+			fmt.Printf("  %s %s\n", colors.WarningIcon(), warning)
+		}
+	}
+}
+
+// conflicts returns warnings about settings that have no effect together
+func conflicts(fs *flag.FlagSet, config *types.Config, sources map[string]source) []string {
+	set := func(name string) bool {
+		src, ok := sources[name]
+		return ok && src.kind != sourceDefault
+	}
+	isPull := strings.HasPrefix(config.Command, "pull ") || config.Command == "pull"
+
+	var warnings []string
+	if config.AllBranches && config.Command != "pull" && fs.Lookup("all-branches") != nil {
+		warnings = append(warnings, fmt.Sprintf("-all-branches only applies to a plain 'pull', but the command is '%s'", config.Command))
+	}
+	if config.Autostash && !isPull && fs.Lookup("autostash") != nil {
+		warnings = append(warnings, fmt.Sprintf("-autostash only applies to pull, but the command is '%s'", config.Command))
+	}
+	if config.StashUntracked && !config.Autostash {
+		warnings = append(warnings, "-stash-untracked has no effect without -autostash")
+	}
+	if set("pull-strategy") && !config.Autostash {
+		warnings = append(warnings, "-pull-strategy has no effect without -autostash")
+	}
+	if set("dirty-states") && !config.IgnoreDirty && !config.Autostash {
+		warnings = append(warnings, "-dirty-states has no effect without -ignore-dirty or -autostash")
+	}
+	if set("workers") && !config.Parallel {
+		warnings = append(warnings, "-workers is ignored with -parallel=false")
+	}
+	if config.FailFast && config.MaxFailures > 0 {
+		warnings = append(warnings, "-max-failures is ignored with -fail-fast")
+	}
+	if config.SkipEmpty && config.OutputMode != types.OutputGrouped {
+		warnings = append(warnings, "-skip-empty only applies to -output grouped")
+	}
+	if f := fs.Lookup("verbose"); f != nil && f.Value.String() == "true" && !config.Verbose {
+		warnings = append(warnings, "-verbose is ignored with -quiet, -tui or -output json")
+	}
+	return warnings
+}
+
+// jsonValue converts a config field for the JSON output of -explain
+func jsonValue(value reflect.Value) any {
+	switch v := value.Interface().(type) {
+	case time.Duration:
+		return v.String()
+	case types.ExitCodes:
+		return map[string]int{"failed": v.Failed, "skipped": v.Skipped, "timeout": v.TimedOut, "none": v.NothingFound}
+	case []string:
+		if v == nil {
+			return []string{}
+		}
+	case map[string]int:
+		if v == nil {
+			return map[string]int{}
+		}
+	}
+	return value.Interface()
+}
+
+// textValue formats a config field for the text output of -explain
+func textValue(value reflect.Value) string {
+	switch v := value.Interface().(type) {
+	case string:
+		if v == "" {
+			return `""`
+		}
+		return v
+	case []string:
+		if len(v) == 0 {
+			return `""`
+		}
+		return strings.Join(v, ",")
+	case map[string]int:
+		if len(v) == 0 {
+			return `""`
+		}
+		entries := make([]string, 0, len(v))
+		for key, limit := range v {
+			entries = append(entries, fmt.Sprintf("%s=%d", key, limit))
+		}
+		sort.Strings(entries)
+		return strings.Join(entries, ",")
+	case types.ExitCodes:
+		return fmt.Sprintf("failed=%d,skipped=%d,timeout=%d,none=%d", v.Failed, v.Skipped, v.TimedOut, v.NothingFound)
+	}
+	return fmt.Sprint(value.Interface())
+}
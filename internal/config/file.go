@@ -0,0 +1,279 @@
+package config
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
+)
+
+// fileEntry is a "key = value" line of the config file
+type fileEntry struct {
+	section string
+	key     string
+	value   string
+	line    int
+}
+
+// profilePrefix starts the header of a section defining a profile, e.g.
+// [profile ci]
+const profilePrefix = "profile "
+
+// profileName returns the profile defined by a section, or "" if the
+// section does not define a profile
+func profileName(section string) string {
+	name, found := strings.CutPrefix(section, profilePrefix)
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(name)
+}
+
+// configFile is a parsed config file. Settings outside any section use the
+// flag names as keys, e.g. "workers = 8". A [profile NAME] section holds
+// settings in the same form that apply on top of them when the profile is
+// selected with -profile.
+type configFile struct {
+	path    string
+	found   bool
+	entries []fileEntry
+}
+
+// FilePath returns the config file read on every run: $RGP_CONFIG if set,
+// otherwise $XDG_CONFIG_HOME/rgp/config
+func FilePath() (string, error) {
+	if path := os.Getenv("RGP_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configDir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configDir, "rgp", "config"), nil
+}
+
+// readConfigFile reads the config file at path. A missing file is empty.
+//
+// The format is line based: blank lines and lines starting with # or ; are
+// ignored, "[name]" starts a section and "key = value" sets a key. Values
+// may be wrapped in double quotes.
+func readConfigFile(path string) (*configFile, error) {
+	file := &configFile{path: path}
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return file, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	file.found = true
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("%s:%d: unterminated section header", path, lineNumber)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, lineNumber)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+			value = value[1 : len(value)-1]
+		}
+
+		file.entries = append(file.entries, fileEntry{section: section, key: key, value: value, line: lineNumber})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// location returns where an entry is defined, as path:line, followed by
+// the profile section if the entry is in one
+func (f *configFile) location(entry fileEntry) string {
+	if profileName(entry.section) != "" {
+		return fmt.Sprintf("%s:%d [%s]", f.path, entry.line, entry.section)
+	}
+	return fmt.Sprintf("%s:%d", f.path, entry.line)
+}
+
+// hasProfile checks if the file defines the profile
+func (f *configFile) hasProfile(name string) bool {
+	for _, entry := range f.entries {
+		if profileName(entry.section) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// cliOnlyFlags are flags that cannot be set in the config file
+var cliOnlyFlags = map[string]bool{"help": true, "h": true, "explain": true}
+
+// fileExcludedFlags are flags that can be set in the environment but not in
+// the config file
+var fileExcludedFlags = map[string]bool{"profile": true}
+
+// applyConfigFile sets the flags of fs from the config file, except flags
+// already given on the command line, and records their sources. Settings of
+// the profile, if one is given, take precedence over the top-level ones.
+// It returns the file and warnings about keys that were ignored.
+func applyConfigFile(fs *flag.FlagSet, sources map[string]source, profile string) (*configFile, []string) {
+	path, err := FilePath()
+	if err != nil {
+		return nil, nil
+	}
+	file, err := readConfigFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid config file: %v", err)))
+		os.Exit(1)
+	}
+	if profile != "" && !file.hasProfile(profile) {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Profile '%s' is not defined in %s%s", profile, file.path, origin(sources, "profile"))))
+		os.Exit(1)
+	}
+
+	known := allFlags()
+	var warnings []string
+	unknownSections := make(map[string]bool)
+	var selected []fileEntry
+	for _, entry := range file.entries {
+		switch {
+		case entry.section == aliasSection:
+			if IsCommand(entry.key) {
+				warnings = append(warnings, fmt.Sprintf("Alias '%s' in %s is ignored because it has the name of a command", entry.key, file.location(entry)))
+			}
+			continue
+		case entry.section != "" && profileName(entry.section) == "":
+			if !unknownSections[entry.section] {
+				unknownSections[entry.section] = true
+				warnings = append(warnings, fmt.Sprintf("Unknown section [%s] in %s", entry.section, file.location(entry)))
+			}
+			continue
+		case cliOnlyFlags[entry.key] || fileExcludedFlags[entry.key]:
+			warnings = append(warnings, fmt.Sprintf("'%s' cannot be set in the config file (%s)", entry.key, file.location(entry)))
+			continue
+		case known.Lookup(entry.key) == nil:
+			warnings = append(warnings, fmt.Sprintf("Unknown key '%s' in %s", entry.key, file.location(entry)))
+			continue
+		}
+
+		// Every profile is checked, but only the selected one applies
+		switch {
+		case entry.section == "":
+			applyFileEntry(fs, sources, file, entry)
+		case profile != "" && profileName(entry.section) == profile:
+			selected = append(selected, entry)
+		}
+	}
+	for _, entry := range selected {
+		applyFileEntry(fs, sources, file, entry)
+	}
+	return file, warnings
+}
+
+// applyFileEntry sets a flag of fs from an entry of the config file unless
+// it was given on the command line. Keys for flags of other commands are
+// ignored.
+func applyFileEntry(fs *flag.FlagSet, sources map[string]source, file *configFile, entry fileEntry) {
+	if fs.Lookup(entry.key) == nil || sources[entry.key].kind == sourceFlag {
+		return
+	}
+	if err := fs.Set(entry.key, entry.value); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.ErrorIcon(), colors.Error(fmt.Sprintf("Invalid value '%s' for '%s' in %s: %v", entry.value, entry.key, file.location(entry), err)))
+		os.Exit(1)
+	}
+	sources[entry.key] = source{kind: sourceFile, location: file.location(entry)}
+}
+
+// Profiles returns the names of the profiles defined in the config file, sorted
+func Profiles() ([]string, error) {
+	path, err := FilePath()
+	if err != nil {
+		return nil, nil
+	}
+	file, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, entry := range file.entries {
+		if name := profileName(entry.section); name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// allFlags returns a flag set with the flags of every command
+func allFlags() *flag.FlagSet {
+	fs := flag.NewFlagSet("rgp", flag.ContinueOnError)
+	registerFlags(fs, discoveryFlags|executionFlags|pullFlags|commandFlags|displayFlags|listFlags, Default(), &flagValues{})
+	return fs
+}
+
+// Setting returns the value of an option set in the environment or in the
+// config file, at the top level or in the profile, without parsing a
+// command line, or "" if it is not set. An empty profile stands for the one
+// selected by RGP_PROFILE, if any.
+func Setting(flagName, profile string) string {
+	if value := os.Getenv(EnvName(flagName)); value != "" {
+		return value
+	}
+	if profile == "" {
+		profile = os.Getenv(EnvName("profile"))
+	}
+
+	path, err := FilePath()
+	if err != nil {
+		return ""
+	}
+	file, err := readConfigFile(path)
+	if err != nil {
+		return ""
+	}
+
+	value, profileValue, inProfile := "", "", false
+	for _, entry := range file.entries {
+		switch {
+		case entry.key != flagName:
+		case entry.section == "":
+			value = entry.value
+		case profile != "" && profileName(entry.section) == profile:
+			profileValue, inProfile = entry.value, true
+		}
+	}
+	if inProfile {
+		return profileValue
+	}
+	return value
+}
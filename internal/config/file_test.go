@@ -0,0 +1,117 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testConfigFile is the config file used by the precedence tests
+const testConfigFile = `
+workers = 8
+timeout = 2m
+
+[profile ci]
+workers = 16
+quiet = true
+
+[profile laptop]
+workers = 2
+`
+
+// useConfigFile points RGP_CONFIG at a temporary file with content and
+// clears the RGP_ variables the tests set
+func useConfigFile(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RGP_CONFIG", path)
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		if strings.HasPrefix(name, envPrefix) && name != "RGP_CONFIG" {
+			t.Setenv(name, "")
+		}
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		workers int
+		quiet   bool
+	}{
+		{name: "config file", workers: 8},
+		{name: "profile flag", args: []string{"-profile", "ci"}, workers: 16, quiet: true},
+		{name: "profile env", env: map[string]string{"RGP_PROFILE": "laptop"}, workers: 2},
+		{name: "profile flag over env", env: map[string]string{"RGP_PROFILE": "laptop"}, args: []string{"-profile", "ci"}, workers: 16, quiet: true},
+		{name: "env over profile", env: map[string]string{"RGP_WORKERS": "3"}, args: []string{"-profile", "ci"}, workers: 3, quiet: true},
+		{name: "flag over env", env: map[string]string{"RGP_WORKERS": "3"}, args: []string{"-workers", "5"}, workers: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfigFile(t, testConfigFile)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			config := ParseSubcommand("pull", tt.args)
+			if config.MaxWorkers != tt.workers {
+				t.Errorf("MaxWorkers = %d, want %d", config.MaxWorkers, tt.workers)
+			}
+			if config.Quiet != tt.quiet {
+				t.Errorf("Quiet = %v, want %v", config.Quiet, tt.quiet)
+			}
+			// Settings the profile leaves alone keep their top-level value
+			if config.Timeout != 2*time.Minute {
+				t.Errorf("Timeout = %s, want 2m0s", config.Timeout)
+			}
+		})
+	}
+}
+
+func TestSetting(t *testing.T) {
+	useConfigFile(t, testConfigFile)
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		profile string
+		want    string
+	}{
+		{name: "config file", want: "8"},
+		{name: "profile", profile: "ci", want: "16"},
+		{name: "profile env", env: map[string]string{"RGP_PROFILE": "laptop"}, want: "2"},
+		{name: "env", env: map[string]string{"RGP_WORKERS": "3"}, profile: "ci", want: "3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			if got := Setting("workers", tt.profile); got != tt.want {
+				t.Errorf("Setting(workers, %q) = %q, want %q", tt.profile, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	useConfigFile(t, testConfigFile+"\n[alias]\nsync = fetch\n\n[profile  ci ]\ntimeout = 5m\n")
+
+	profiles, err := Profiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ci", "laptop"}; !reflect.DeepEqual(profiles, want) {
+		t.Errorf("Profiles() = %v, want %v", profiles, want)
+	}
+}
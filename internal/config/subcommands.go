@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// Subcommand describes an rgp subcommand and the flags it accepts
+type Subcommand struct {
+	Name        string
+	Usage       string
+	Description string
+
+	flags flagGroup
+
+	// command builds the command to run from the positional arguments.
+	// Subcommands without it keep the -command flag or its default.
+	command func(args []string) (string, error)
+
+	// defaults adjusts the default configuration before flags are applied
+	defaults func(config *types.Config)
+}
+
+// legacyCommand is the flat interface used when no subcommand is given
+var legacyCommand = &Subcommand{
+	Name:  "rgp",
+	Usage: "rgp [options]",
+	flags: discoveryFlags | executionFlags | pullFlags | commandFlags | displayFlags,
+}
+
+// Subcommands lists the subcommands parsed by ParseSubcommand, in help order
+var Subcommands = []*Subcommand{
+	{
+		Name:        "pull",
+		Usage:       "rgp pull [options] [-- <git pull args>]",
+		Description: "Pull every repository",
+		flags:       discoveryFlags | executionFlags | pullFlags | displayFlags,
+		command:     gitCommand("pull"),
+	},
+	{
+		Name:        "status",
+		Usage:       "rgp status [options] [-- <git status args>]",
+		Description: "Show the status of every repository",
+		flags:       discoveryFlags | executionFlags | displayFlags,
+		command:     gitCommand("status"),
+	},
+	{
+		Name:        "run",
+		Usage:       "rgp run [options] [--] <git args>",
+		Description: "Run any git command in every repository",
+		flags:       discoveryFlags | executionFlags | pullFlags | displayFlags,
+		command: func(args []string) (string, error) {
+			if len(args) == 0 {
+				return "", errors.New("rgp run expects a git command, e.g. 'rgp run fetch --prune'")
+			}
+			return strings.Join(args, " "), nil
+		},
+	},
+	{
+		Name:        "exec",
+		Usage:       "rgp exec [options] [--] <shell command>",
+		Description: "Run a shell command in every repository",
+		flags:       discoveryFlags | executionFlags | displayFlags,
+		command: func(args []string) (string, error) {
+			if len(args) == 0 {
+				return "", errors.New("rgp exec expects a shell command, e.g. 'rgp exec -- make test'")
+			}
+			return "!" + strings.Join(args, " "), nil
+		},
+	},
+	{
+		Name:        "list",
+		Usage:       "rgp list [options]",
+		Description: "List the repositories matching the filters without running anything",
+		flags:       discoveryFlags | listFlags | displayFlags,
+	},
+	{
+		Name:        "retry-failed",
+		Usage:       "rgp retry-failed [options]",
+		Description: "Re-run the last command on repositories that failed",
+		flags:       discoveryFlags | executionFlags | pullFlags | displayFlags,
+		defaults:    func(config *types.Config) { config.OnlyFailed = true },
+	},
+}
+
+// FindSubcommand returns the subcommand with the given name, or nil
+func FindSubcommand(name string) *Subcommand {
+	for _, sub := range Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// FlagSet returns the flags accepted by the subcommand with the given name,
+// or by the flat interface when name is empty
+func FlagSet(name string) *flag.FlagSet {
+	sub := legacyCommand
+	if name != "" {
+		if sub = FindSubcommand(name); sub == nil {
+			return nil
+		}
+	}
+
+	fs := flag.NewFlagSet(sub.Name, flag.ContinueOnError)
+	registerFlags(fs, sub.flags, Default(), &flagValues{})
+	return fs
+}
+
+// ParseSubcommand parses the arguments given after a subcommand and returns
+// configuration, or nil if there is no subcommand with the given name
+func ParseSubcommand(name string, args []string) *types.Config {
+	sub := FindSubcommand(name)
+	if sub == nil {
+		return nil
+	}
+	return parse(sub, args)
+}
+
+// gitCommand builds a command running the git subcommand with the positional arguments
+func gitCommand(subcommand string) func(args []string) (string, error) {
+	return func(args []string) (string, error) {
+		return strings.Join(append([]string{subcommand}, args...), " "), nil
+	}
+}
+
+// showUsage prints the help of a subcommand to stdout
+func showUsage(sub *Subcommand, fs *flag.FlagSet) {
+	fs.SetOutput(os.Stdout)
+	if sub == legacyCommand {
+		showHelp(fs)
+		return
+	}
+
+	fmt.Println("Usage:")
+	fmt.Printf("  %s\n", sub.Usage)
+	fmt.Println("")
+	fmt.Println(sub.Description)
+	fmt.Println("")
+	fmt.Println("Options:")
+	fs.PrintDefaults()
+}
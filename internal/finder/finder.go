@@ -53,7 +53,7 @@ func shouldSkipRepository(repoName string, includePatterns, excludePatterns []st
 	if len(includePatterns) > 0 {
 		matched := false
 		for _, pattern := range includePatterns {
-			if matched, _ := filepath.Match(pattern, repoName); matched {
+			if ok, _ := filepath.Match(pattern, repoName); ok {
 				matched = true
 				break
 			}
@@ -0,0 +1,58 @@
+package finder
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestShouldSkipRepository(t *testing.T) {
+	tests := []struct {
+		name    string
+		repo    string
+		include []string
+		exclude []string
+		skip    bool
+	}{
+		{"no patterns", "api", nil, nil, false},
+		{"include matches", "api", []string{"api"}, nil, false},
+		{"include glob matches", "api-users", []string{"web-*", "api-*"}, nil, false},
+		{"include does not match", "web", []string{"api-*"}, nil, true},
+		{"exclude matches", "api-old", nil, []string{"*-old"}, true},
+		{"exclude wins over include", "api-old", []string{"api-*"}, []string{"*-old"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldSkipRepository(tt.repo, tt.include, tt.exclude); got != tt.skip {
+				t.Errorf("shouldSkipRepository(%q, %v, %v) = %v, want %v", tt.repo, tt.include, tt.exclude, got, tt.skip)
+			}
+		})
+	}
+}
+
+func TestFindRepositoriesInclude(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"api-users", "api-orders", "web"} {
+		if err := os.MkdirAll(filepath.Join(root, name, ".git"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repositories, err := FindRepositories(root, []string{"api-*"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, repo := range repositories {
+		names = append(names, repo.Name)
+	}
+	sort.Strings(names)
+
+	want := []string{"api-orders", "api-users"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("FindRepositories with include [api-*] = %v, want %v", names, want)
+	}
+}
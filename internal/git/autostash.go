@@ -0,0 +1,140 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// autostashMessage identifies stashes created by rgp
+const autostashMessage = "rgp autostash"
+
+// pullWithAutostash stashes local changes, pulls and restores the stash afterwards.
+// Extra pull arguments are passed through after the pull strategy option.
+func (e *Executor) pullWithAutostash(ctx context.Context, repo *types.Repository, args []string, start time.Time) *types.ExecutionResult {
+	pullArgs := append([]string{"pull"}, e.pullOptions(args)...)
+	result := &types.ExecutionResult{
+		Repository: repo,
+		Command:    strings.Join(pullArgs, " "),
+		Success:    false,
+	}
+
+	var outputs []string
+	finish := func() *types.ExecutionResult {
+		result.Output = strings.Join(outputs, "\n")
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	// Stash local changes, remembering the previous stash so we can tell
+	// whether anything was actually stashed
+	previousStash := e.stashRef(ctx, repo.Path)
+
+	stashArgs := []string{"stash", "push", "-m", autostashMessage}
+	if e.config.StashUntracked {
+		stashArgs = append(stashArgs, "--include-untracked")
+	}
+
+	output, err := e.runGit(ctx, repo.Path, stashArgs...)
+	outputs = append(outputs, output)
+	if err != nil {
+		result.Error = fmt.Sprintf("Error stashing changes: %v", err)
+		return finish()
+	}
+
+	currentStash := e.stashRef(ctx, repo.Path)
+	stashed := currentStash != "" && currentStash != previousStash
+
+	// Pull using the configured strategy
+	var pullErr error
+	if e.config.AllBranches {
+		pullResult := e.pullAllBranches(ctx, repo, e.pullOptions(args), start)
+		outputs = append(outputs, pullResult.Output)
+		if !pullResult.Success {
+			pullErr = fmt.Errorf("%s", pullResult.Error)
+			result.TimedOut = pullResult.TimedOut
+		}
+	} else {
+		output, pullErr = e.runGit(ctx, repo.Path, pullArgs...)
+		outputs = append(outputs, output)
+	}
+
+	// A failed rebase must be aborted before the stash can be restored
+	if pullErr != nil && e.config.PullStrategy == types.PullStrategyRebase && e.isRebaseInProgress(ctx, repo.Path) {
+		output, _ = e.runGit(ctx, repo.Path, "rebase", "--abort")
+		outputs = append(outputs, output)
+	}
+
+	if stashed {
+		output, err = e.runGit(ctx, repo.Path, "stash", "pop")
+		outputs = append(outputs, output)
+		if err != nil {
+			// Undo the partly applied stash so the stash is the only copy
+			// of the local changes and the worktree is left clean
+			output, _ = e.runGit(ctx, repo.Path, "reset", "--merge")
+			outputs = append(outputs, output)
+
+			result.NeedsAttention = true
+			result.Error = fmt.Sprintf("Stash could not be reapplied cleanly, changes kept in stash (needs attention): %v", err)
+			if pullErr != nil {
+				result.Error = fmt.Sprintf("Error pulling: %v; %s", pullErr, result.Error)
+			}
+			return finish()
+		}
+	}
+
+	if pullErr != nil {
+		result.Error = fmt.Sprintf("Error pulling: %v", pullErr)
+		result.TimedOut = result.TimedOut || isTimeout(pullErr)
+		return finish()
+	}
+
+	result.Success = true
+	return finish()
+}
+
+// pullOptions returns the options of every git pull: the pull strategy in
+// autostash mode, followed by the extra pull arguments
+func (e *Executor) pullOptions(args []string) []string {
+	if !e.config.Autostash {
+		return args
+	}
+	return append([]string{"--" + e.config.PullStrategy}, args...)
+}
+
+// stashRef returns the commit of the most recent stash, or an empty string if there is none
+func (e *Executor) stashRef(ctx context.Context, repoPath string) string {
+	output, err := e.runGit(ctx, repoPath, "rev-parse", "-q", "--verify", "refs/stash")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// isRebaseInProgress checks if the repository is in the middle of a rebase
+func (e *Executor) isRebaseInProgress(ctx context.Context, repoPath string) bool {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		output, err := e.runGit(ctx, repoPath, "rev-parse", "--git-path", dir)
+		if err != nil {
+			continue
+		}
+		if isDir(repoPath, strings.TrimSpace(output)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDir checks if path, relative to the repository unless absolute, is a directory
+func isDir(repoPath, path string) bool {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(repoPath, path)
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,109 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// runTestGit runs git in dir and fails the test on error
+func runTestGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	args = append([]string{"-c", "user.name=rgp", "-c", "user.email=rgp@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return string(output)
+}
+
+// writeTestFile writes content to name in dir
+func writeTestFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// cloneWithUpstreamChange creates an upstream repository and a clone of it,
+// then commits a change to f upstream that the clone has not pulled yet
+func cloneWithUpstreamChange(t *testing.T) (upstream, clone string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	upstream, clone = t.TempDir(), t.TempDir()
+	runTestGit(t, upstream, "init", "-q", "-b", "main")
+	writeTestFile(t, upstream, "f", "base\n")
+	runTestGit(t, upstream, "add", "f")
+	runTestGit(t, upstream, "commit", "-q", "-m", "base")
+	runTestGit(t, clone, "clone", "-q", upstream, ".")
+
+	writeTestFile(t, upstream, "f", "upstream\n")
+	runTestGit(t, upstream, "commit", "-q", "-am", "upstream")
+	return upstream, clone
+}
+
+// autostashExecutor returns an executor pulling with autostash
+func autostashExecutor(strategy string) *Executor {
+	return NewExecutor(&types.Config{
+		MaxWorkers:   1,
+		Timeout:      30 * time.Second,
+		DirtyStates:  types.AllDirtyStates,
+		Autostash:    true,
+		PullStrategy: strategy,
+	})
+}
+
+func TestPullWithAutostashPopConflict(t *testing.T) {
+	_, clone := cloneWithUpstreamChange(t)
+	writeTestFile(t, clone, "f", "local\n")
+
+	repo := &types.Repository{Path: clone, Name: "clone"}
+	result := autostashExecutor(types.PullStrategyFFOnly).ExecuteCommand(context.Background(), repo, "pull")
+
+	if result.Success || !result.NeedsAttention {
+		t.Fatalf("result: success=%v needsAttention=%v, want a failure needing attention (error: %s)", result.Success, result.NeedsAttention, result.Error)
+	}
+	if status := runTestGit(t, clone, "status", "--porcelain"); status != "" {
+		t.Errorf("worktree not clean after a failed stash pop:\n%s", status)
+	}
+	if stashes := strings.Split(strings.TrimSpace(runTestGit(t, clone, "stash", "list")), "\n"); len(stashes) != 1 || !strings.Contains(stashes[0], autostashMessage) {
+		t.Errorf("stash list = %q, want the autostash entry only", stashes)
+	}
+	if diff := runTestGit(t, clone, "stash", "show", "-p"); !strings.Contains(diff, "+local") {
+		t.Errorf("stash does not hold the local change:\n%s", diff)
+	}
+}
+
+func TestPullWithAutostashUsesStrategyOnCleanRepositories(t *testing.T) {
+	_, clone := cloneWithUpstreamChange(t)
+
+	// Diverge from upstream so a plain pull would create a merge commit
+	writeTestFile(t, clone, "g", "local\n")
+	runTestGit(t, clone, "add", "g")
+	runTestGit(t, clone, "commit", "-q", "-m", "local")
+	head := runTestGit(t, clone, "rev-parse", "HEAD")
+
+	repo := &types.Repository{Path: clone, Name: "clone"}
+	result := autostashExecutor(types.PullStrategyFFOnly).ExecuteCommand(context.Background(), repo, "pull")
+
+	if result.Success {
+		t.Fatalf("pull of a diverged branch succeeded with --ff-only (command: %s)", result.Command)
+	}
+	if result.Command != "pull --ff-only" {
+		t.Errorf("command = %q, want %q", result.Command, "pull --ff-only")
+	}
+	if got := runTestGit(t, clone, "rev-parse", "HEAD"); got != head {
+		t.Errorf("HEAD moved from %s to %s", head, got)
+	}
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// shortstatPattern matches the parts of `git diff --shortstat` output
+var shortstatPattern = regexp.MustCompile(`(\d+) (files? changed|insertions?\(\+\)|deletions?\(-\))`)
+
+// headCommit returns the commit HEAD points to, or an empty string if it cannot be resolved
+func (e *Executor) headCommit(ctx context.Context, repoPath string) string {
+	output, err := e.runGit(ctx, repoPath, "rev-parse", "--verify", "-q", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// CurrentBranch returns the branch checked out in the repository, or an
+// empty string when HEAD is detached
+func (e *Executor) CurrentBranch(ctx context.Context, repoPath string) string {
+	output, err := e.runGit(ctx, repoPath, "symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// recordChanges compares HEAD with the commit recorded before the command and
+// stores what changed in the result
+func (e *Executor) recordChanges(ctx context.Context, result *types.ExecutionResult, oldHead string) {
+	if oldHead == "" {
+		return
+	}
+
+	newHead := e.headCommit(ctx, result.Repository.Path)
+	if newHead == "" {
+		return
+	}
+
+	changes := &types.ChangeSummary{
+		OldHead: oldHead,
+		NewHead: newHead,
+		Kind:    types.UpdateNone,
+	}
+	result.Changes = changes
+
+	if oldHead == newHead {
+		return
+	}
+
+	changes.Kind = e.updateKind(ctx, result.Repository.Path, oldHead, newHead)
+
+	if output, err := e.runGit(ctx, result.Repository.Path, "rev-list", "--count", oldHead+".."+newHead); err == nil {
+		changes.IncomingCommits, _ = strconv.Atoi(strings.TrimSpace(output))
+	}
+
+	if output, err := e.runGit(ctx, result.Repository.Path, "diff", "--shortstat", oldHead, newHead); err == nil {
+		for _, match := range shortstatPattern.FindAllStringSubmatch(output, -1) {
+			count, _ := strconv.Atoi(match[1])
+			switch {
+			case strings.HasPrefix(match[2], "file"):
+				changes.FilesChanged = count
+			case strings.HasPrefix(match[2], "insertion"):
+				changes.Insertions = count
+			case strings.HasPrefix(match[2], "deletion"):
+				changes.Deletions = count
+			}
+		}
+	}
+}
+
+// updateKind determines how HEAD moved from oldHead to newHead
+func (e *Executor) updateKind(ctx context.Context, repoPath, oldHead, newHead string) string {
+	if _, err := e.runGit(ctx, repoPath, "merge-base", "--is-ancestor", oldHead, newHead); err != nil {
+		return types.UpdateRebase
+	}
+
+	output, err := e.runGit(ctx, repoPath, "rev-list", "--parents", "-n", "1", newHead)
+	if err == nil {
+		parents := strings.Fields(output)
+		if len(parents) > 2 && parents[1] == oldHead {
+			return types.UpdateMerge
+		}
+	}
+	return types.UpdateFastForward
+}
@@ -0,0 +1,134 @@
+package git
+
+import "strings"
+
+// readOnlySubcommands lists git subcommands that never modify the working tree or HEAD
+var readOnlySubcommands = map[string]bool{
+	"blame":        true,
+	"branch":       true,
+	"cat-file":     true,
+	"describe":     true,
+	"diff":         true,
+	"fetch":        true,
+	"for-each-ref": true,
+	"grep":         true,
+	"help":         true,
+	"log":          true,
+	"ls-files":     true,
+	"ls-remote":    true,
+	"ls-tree":      true,
+	"remote":       true,
+	"rev-list":     true,
+	"rev-parse":    true,
+	"shortlog":     true,
+	"show":         true,
+	"show-ref":     true,
+	"status":       true,
+	"tag":          true,
+	"version":      true,
+}
+
+// mutatingSubcommands lists git subcommands that may modify the working tree, the index or HEAD
+var mutatingSubcommands = map[string]bool{
+	"add":         true,
+	"am":          true,
+	"apply":       true,
+	"checkout":    true,
+	"cherry-pick": true,
+	"clean":       true,
+	"commit":      true,
+	"merge":       true,
+	"mv":          true,
+	"pull":        true,
+	"rebase":      true,
+	"reset":       true,
+	"restore":     true,
+	"revert":      true,
+	"rm":          true,
+	"stash":       true,
+	"submodule":   true,
+	"switch":      true,
+}
+
+// integratingSubcommands lists git subcommands that bring other commits into
+// the current branch, whose changes are reported
+var integratingSubcommands = map[string]bool{
+	"merge":  true,
+	"pull":   true,
+	"rebase": true,
+}
+
+// networkSubcommands lists git subcommands that talk to a remote
+var networkSubcommands = map[string]bool{
+	"clone":     true,
+	"fetch":     true,
+	"ls-remote": true,
+	"pull":      true,
+	"push":      true,
+	"remote":    true,
+	"submodule": true,
+}
+
+// globalOptionsWithValue lists git global options that take a separate value argument
+var globalOptionsWithValue = map[string]bool{
+	"-C":          true,
+	"-c":          true,
+	"--git-dir":   true,
+	"--work-tree": true,
+	"--namespace": true,
+}
+
+// ParseSubcommand splits a git command line into its subcommand and the
+// remaining arguments, skipping any global options placed before the subcommand
+func ParseSubcommand(command string) (string, []string) {
+	fields := strings.Fields(command)
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		if globalOptionsWithValue[field] {
+			i++
+			continue
+		}
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		return field, fields[i+1:]
+	}
+	return "", nil
+}
+
+// IsMutatingCommand reports whether the git command may modify the repository.
+// Subcommands that are not known to be read-only are treated as mutating.
+func IsMutatingCommand(command string) bool {
+	subcommand, _ := ParseSubcommand(command)
+	if mutatingSubcommands[subcommand] {
+		return true
+	}
+	return !readOnlySubcommands[subcommand]
+}
+
+// IsIntegratingCommand reports whether the git command brings other commits
+// into the current branch, like pull, merge and rebase
+func IsIntegratingCommand(command string) bool {
+	subcommand, _ := ParseSubcommand(command)
+	return integratingSubcommands[subcommand]
+}
+
+// IsNetworkCommand reports whether the git command may connect to a remote
+func IsNetworkCommand(command string) bool {
+	subcommand, _ := ParseSubcommand(command)
+	return networkSubcommands[subcommand]
+}
+
+// IsShellCommand reports whether the command is a shell command rather than
+// git arguments. Like git aliases, shell commands start with "!".
+func IsShellCommand(command string) bool {
+	return strings.HasPrefix(command, "!")
+}
+
+// DisplayCommand returns the command line as it is run in each repository
+func DisplayCommand(command string) string {
+	if IsShellCommand(command) {
+		return strings.TrimPrefix(command, "!")
+	}
+	return "git " + command
+}
@@ -0,0 +1,55 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSubcommand(t *testing.T) {
+	tests := []struct {
+		command    string
+		subcommand string
+		args       []string
+	}{
+		{"pull", "pull", []string{}},
+		{"pull --rebase origin main", "pull", []string{"--rebase", "origin", "main"}},
+		{"--no-pager log -1", "log", []string{"-1"}},
+		{"-c core.pager=cat -C sub status --short", "status", []string{"--short"}},
+		{"--git-dir .git --work-tree . checkout main", "checkout", []string{"main"}},
+		{"--version", "", nil},
+		{"", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			subcommand, args := ParseSubcommand(tt.command)
+			if subcommand != tt.subcommand || !reflect.DeepEqual(args, tt.args) {
+				t.Errorf("ParseSubcommand(%q) = %q, %q, want %q, %q", tt.command, subcommand, args, tt.subcommand, tt.args)
+			}
+		})
+	}
+}
+
+func TestIsMutatingCommand(t *testing.T) {
+	tests := []struct {
+		command  string
+		mutating bool
+	}{
+		{"pull", true},
+		{"checkout main", true},
+		{"reset --hard", true},
+		{"-c core.pager=cat stash", true},
+		{"status --short", false},
+		{"--no-pager log -1", false},
+		{"fetch --all --prune", false},
+		{"unknown-subcommand", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			if got := IsMutatingCommand(tt.command); got != tt.mutating {
+				t.Errorf("IsMutatingCommand(%q) = %v, want %v", tt.command, got, tt.mutating)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// ErrCancelled is the error of repositories cancelled with Cancel
+var ErrCancelled = errors.New("Cancelled by user")
+
+// EventType identifies what an Event reports
+type EventType int
+
+// Event types, in the order they occur for a repository
+const (
+	EventQueued EventType = iota
+	EventRetrying
+	EventStarted
+	EventOutputLine
+	EventFinished
+)
+
+// eventTypeNames are the names returned by EventType.String
+var eventTypeNames = map[EventType]string{
+	EventQueued:     "queued",
+	EventRetrying:   "retrying",
+	EventStarted:    "started",
+	EventOutputLine: "output",
+	EventFinished:   "finished",
+}
+
+func (t EventType) String() string {
+	if name, ok := eventTypeNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Event reports the progress of a repository during a run
+type Event struct {
+	Type       EventType
+	Repository *types.Repository
+	Command    string
+	Time       time.Time
+
+	// Line and Stderr are set for EventOutputLine
+	Line   string
+	Stderr bool
+
+	// Attempt is set for EventRetrying, starting at 2 for the first retry
+	Attempt int
+
+	// Result is set for EventFinished
+	Result *types.ExecutionResult
+}
+
+// EventHandler receives events as they happen. It may be called
+// concurrently for different repositories, and blocks the repository's
+// work until it returns.
+type EventHandler func(event Event)
+
+// eventSubscription is a registered event handler
+type eventSubscription struct {
+	id      int
+	handler EventHandler
+}
+
+// AddEventHandler registers a handler receiving the events of every
+// repository and returns a function removing it. Handlers are called in
+// the order they were added. Events being emitted while the handler is
+// removed may still reach it; use WithEventHandler to receive the events
+// of a single run.
+func (e *Executor) AddEventHandler(handler EventHandler) func() {
+	e.handlersMu.Lock()
+	defer e.handlersMu.Unlock()
+
+	e.nextHandlerID++
+	id := e.nextHandlerID
+	e.handlers = append(e.handlers, eventSubscription{id: id, handler: handler})
+
+	return func() {
+		e.handlersMu.Lock()
+		defer e.handlersMu.Unlock()
+
+		handlers := make([]eventSubscription, 0, len(e.handlers))
+		for _, sub := range e.handlers {
+			if sub.id != id {
+				handlers = append(handlers, sub)
+			}
+		}
+		e.handlers = handlers
+	}
+}
+
+// runHandlersKey is the context key of the event handlers of a single run
+type runHandlersKey struct{}
+
+// WithEventHandler returns a context whose runs also report their events to
+// handler. Unlike AddEventHandler, the handler only receives the events of
+// commands run with the returned context, so concurrent runs on the same
+// executor do not see each other's events.
+func WithEventHandler(ctx context.Context, handler EventHandler) context.Context {
+	handlers, _ := ctx.Value(runHandlersKey{}).([]EventHandler)
+	handlers = append(handlers[:len(handlers):len(handlers)], handler)
+	return context.WithValue(ctx, runHandlersKey{}, handlers)
+}
+
+// Stream runs the command on the repositories like
+// ExecuteCommandOnRepositories, in the background, and returns a channel
+// receiving the events of the run. The channel is closed after the last
+// event and must be drained, since workers wait for it to accept events.
+func (e *Executor) Stream(ctx context.Context, repositories []*types.Repository, command string) <-chan Event {
+	events := make(chan Event, 64)
+	ctx = WithEventHandler(ctx, func(event Event) { events <- event })
+
+	go func() {
+		// Every event of the run is emitted before the run returns
+		defer close(events)
+		e.ExecuteCommandOnRepositories(ctx, repositories, command)
+	}()
+	return events
+}
+
+// hasEventHandlers checks if any event handler receives the events of
+// commands run with ctx
+func (e *Executor) hasEventHandlers(ctx context.Context) bool {
+	if handlers, _ := ctx.Value(runHandlersKey{}).([]EventHandler); len(handlers) > 0 {
+		return true
+	}
+
+	e.handlersMu.RLock()
+	defer e.handlersMu.RUnlock()
+	return len(e.handlers) > 0
+}
+
+// emit sends an event to the registered event handlers, then to the
+// handlers of the run carried by ctx
+func (e *Executor) emit(ctx context.Context, event Event) {
+	e.handlersMu.RLock()
+	handlers := e.handlers
+	e.handlersMu.RUnlock()
+	runHandlers, _ := ctx.Value(runHandlersKey{}).([]EventHandler)
+
+	if len(handlers) == 0 && len(runHandlers) == 0 {
+		return
+	}
+	event.Time = time.Now()
+	for _, sub := range handlers {
+		sub.handler(event)
+	}
+	for _, handler := range runHandlers {
+		handler(event)
+	}
+}
+
+// Retry runs the command in the repository again, reporting the attempt
+// with an EventRetrying before the usual events
+func (e *Executor) Retry(ctx context.Context, repo *types.Repository, command string) *types.ExecutionResult {
+	e.runningMu.Lock()
+	e.attempts[repo.Path]++
+	attempt := e.attempts[repo.Path] + 1
+	e.runningMu.Unlock()
+
+	e.emit(ctx, Event{Type: EventRetrying, Repository: repo, Command: command, Attempt: attempt})
+	return e.ExecuteCommand(ctx, repo, command)
+}
+
+// Cancel stops the command running in the repository, or skips the
+// repository if it is still queued. The repository fails with ErrCancelled.
+// Repositories that are neither running nor queued are left alone.
+func (e *Executor) Cancel(repo *types.Repository) {
+	e.runningMu.Lock()
+	defer e.runningMu.Unlock()
+
+	if cancel, ok := e.running[repo.Path]; ok {
+		cancel(ErrCancelled)
+		return
+	}
+	if e.queued[repo.Path] {
+		e.cancelled[repo.Path] = true
+	}
+}
+
+// enqueue registers the repositories of a new run as queued, dropping the
+// cancellations and retry counts left over from earlier runs
+func (e *Executor) enqueue(repositories []*types.Repository) {
+	e.runningMu.Lock()
+	defer e.runningMu.Unlock()
+
+	for _, repo := range repositories {
+		e.queued[repo.Path] = true
+		delete(e.cancelled, repo.Path)
+		delete(e.attempts, repo.Path)
+	}
+}
+
+// dequeue removes the repository from the queue without running it
+func (e *Executor) dequeue(repo *types.Repository) {
+	e.runningMu.Lock()
+	defer e.runningMu.Unlock()
+
+	delete(e.queued, repo.Path)
+	delete(e.cancelled, repo.Path)
+}
+
+// track moves the repository from the queue to the running repositories so
+// it can be cancelled. A cancellation that arrived while it was still
+// queued takes effect at once. The returned function unregisters it.
+func (e *Executor) track(ctx context.Context, repo *types.Repository) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	e.runningMu.Lock()
+	if e.cancelled[repo.Path] {
+		cancel(ErrCancelled)
+	}
+	delete(e.queued, repo.Path)
+	delete(e.cancelled, repo.Path)
+	e.running[repo.Path] = cancel
+	e.runningMu.Unlock()
+
+	return ctx, func() {
+		e.runningMu.Lock()
+		delete(e.running, repo.Path)
+		e.runningMu.Unlock()
+		cancel(nil)
+	}
+}
+
+// isCancelled checks if the queued repository was cancelled
+func (e *Executor) isCancelled(repo *types.Repository) bool {
+	e.runningMu.Lock()
+	defer e.runningMu.Unlock()
+	return e.cancelled[repo.Path]
+}
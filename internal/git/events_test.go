@@ -0,0 +1,56 @@
+package git
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+func TestCancelOnlyAffectsQueuedRepositories(t *testing.T) {
+	_, clone := cloneWithUpstreamChange(t)
+	repo := &types.Repository{Name: "clone", Path: clone}
+	executor := NewExecutor(&types.Config{MaxWorkers: 1, Timeout: 30 * time.Second})
+	ctx := context.Background()
+
+	// Cancelling a repository that is not part of a run has no lasting effect
+	executor.Cancel(repo)
+	results := executor.ExecuteCommandOnRepositories(ctx, []*types.Repository{repo}, "status")
+	if !results[0].Success {
+		t.Fatalf("run after a stale cancel failed: %s", results[0].Error)
+	}
+	executor.Cancel(repo)
+	if result := executor.Retry(ctx, repo, "status"); !result.Success {
+		t.Fatalf("retry after a stale cancel failed: %s", result.Error)
+	}
+}
+
+func TestRetryAttemptsStartOverEachRun(t *testing.T) {
+	_, clone := cloneWithUpstreamChange(t)
+	repo := &types.Repository{Name: "clone", Path: clone}
+	executor := NewExecutor(&types.Config{MaxWorkers: 1, Timeout: 30 * time.Second})
+
+	var attempts []int
+	ctx := WithEventHandler(context.Background(), func(event Event) {
+		if event.Type == EventRetrying {
+			attempts = append(attempts, event.Attempt)
+		}
+	})
+
+	for range 2 {
+		executor.ExecuteCommandOnRepositories(ctx, []*types.Repository{repo}, "status")
+		executor.Retry(ctx, repo, "status")
+		executor.Retry(ctx, repo, "status")
+	}
+
+	want := []int{2, 3, 2, 3}
+	if len(attempts) != len(want) {
+		t.Fatalf("attempts = %v, want %v", attempts, want)
+	}
+	for i := range want {
+		if attempts[i] != want[i] {
+			t.Fatalf("attempts = %v, want %v", attempts, want)
+		}
+	}
+}
@@ -2,29 +2,87 @@ package git
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os/exec"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
 	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
 )
 
+// waitDelay bounds how long a cancelled command may keep its output open,
+// e.g. when git spawned a child process that outlives it
+const waitDelay = time.Second
+
 // Executor handles Git command execution
 type Executor struct {
 	config *types.Config
+
+	// Per-host concurrency slots, created on first use
+	hostPatterns []string
+	hostSlots    map[string]chan struct{}
+	hostMu       sync.Mutex
+
+	// Handlers receiving the events of every repository
+	handlers      []eventSubscription
+	nextHandlerID int
+	handlersMu    sync.RWMutex
+
+	// Running and queued repositories, queued repositories cancelled with
+	// Cancel and the number of retries of each repository in the last run
+	running   map[string]context.CancelCauseFunc
+	queued    map[string]bool
+	cancelled map[string]bool
+	attempts  map[string]int
+	runningMu sync.Mutex
 }
 
 // NewExecutor creates a new Git executor
 func NewExecutor(config *types.Config) *Executor {
-	return &Executor{config: config}
+	// Match the most specific host patterns first
+	hostPatterns := make([]string, 0, len(config.HostLimits))
+	for pattern := range config.HostLimits {
+		hostPatterns = append(hostPatterns, pattern)
+	}
+	sort.Slice(hostPatterns, func(i, j int) bool {
+		if len(hostPatterns[i]) != len(hostPatterns[j]) {
+			return len(hostPatterns[i]) > len(hostPatterns[j])
+		}
+		return hostPatterns[i] < hostPatterns[j]
+	})
+
+	return &Executor{
+		config:       config,
+		hostPatterns: hostPatterns,
+		hostSlots:    make(map[string]chan struct{}),
+		running:      make(map[string]context.CancelCauseFunc),
+		queued:       make(map[string]bool),
+		cancelled:    make(map[string]bool),
+		attempts:     make(map[string]int),
+	}
+}
+
+// ExecuteCommand executes a Git command in a single repository.
+// Every step runs under ctx. The configured repository deadline starts once
+// the repository holds a slot on its remote host.
+func (e *Executor) ExecuteCommand(ctx context.Context, repo *types.Repository, command string) *types.ExecutionResult {
+	ctx, done := e.track(ctx, repo)
+	defer done()
+
+	e.emit(ctx, Event{Type: EventStarted, Repository: repo, Command: command})
+	result := e.executeCommand(ctx, repo, command)
+	e.emit(ctx, Event{Type: EventFinished, Repository: repo, Command: command, Result: result})
+	return result
 }
 
-// ExecuteCommand executes a Git command in a single repository
-func (e *Executor) ExecuteCommand(repo *types.Repository, command string) *types.ExecutionResult {
+// executeCommand runs all steps of a command in a single repository
+func (e *Executor) executeCommand(ctx context.Context, repo *types.Repository, command string) *types.ExecutionResult {
 	start := time.Now()
 	result := &types.ExecutionResult{
 		Repository: repo,
@@ -33,111 +91,189 @@ func (e *Executor) ExecuteCommand(repo *types.Repository, command string) *types
 		Duration:   0,
 	}
 
-	// Check if we should ignore dirty repositories
-	if e.config.IgnoreDirty && command == "pull" {
-		if isDirty, err := e.isRepositoryDirty(repo.Path); err != nil {
+	// Check if we should ignore or autostash dirty repositories
+	subcommand, args := ParseSubcommand(command)
+	autostash := e.config.Autostash && subcommand == "pull"
+	stashNeeded := false
+	if (e.config.IgnoreDirty && IsMutatingCommand(command)) || autostash {
+		dirtyStates, rebasing, err := e.repositoryDirtyStates(ctx, repo.Path)
+		if err != nil {
 			result.Error = fmt.Sprintf("Error checking repository status: %v", err)
 			result.Duration = time.Since(start)
 			return result
-		} else if isDirty {
-			result.Error = "Repository has uncommitted changes (skipped)"
+		}
+
+		// Never stash in the middle of a rebase, even when a rebase in
+		// progress is not one of the configured dirty states
+		if len(dirtyStates) > 0 && autostash && rebasing && !containsState(dirtyStates, types.DirtyRebase) {
+			dirtyStates = append(dirtyStates, types.DirtyRebase)
+		}
+
+		if len(dirtyStates) > 0 && autostash && !containsState(dirtyStates, types.DirtyRebase) {
+			stashNeeded = true
+		} else if len(dirtyStates) > 0 && (e.config.IgnoreDirty || autostash) {
+			result.Error = fmt.Sprintf("Repository has %s (skipped)", describeDirtyStates(dirtyStates))
+			result.Skipped = true
 			result.Duration = time.Since(start)
 			return result
 		}
 	}
 
-	// Handle special case for pull all branches
-	if command == "pull" && e.config.AllBranches {
-		return e.pullAllBranches(repo, start)
+	// Throttle network-bound commands per remote host. Dirty repositories
+	// that are skipped never wait for a slot.
+	release, err := e.acquireHostSlot(ctx, repo.Path, command)
+	if err != nil {
+		result.Error = err.Error()
+		result.Duration = time.Since(start)
+		return result
+	}
+	defer release()
+
+	// Apply the per-repository deadline once a host slot is held, so waiting
+	// for the host does not count against it
+	if e.config.RepoTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, e.config.RepoTimeout, &TimeoutError{Scope: "Repository", Timeout: e.config.RepoTimeout})
+		defer cancel()
 	}
 
-	// Execute the command with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), e.config.Timeout)
-	defer cancel()
+	// Record HEAD before pulls, merges and rebases to report what changed.
+	// Other commands moving HEAD, like checkout or reset, are not updates.
+	oldHead := ""
+	if IsIntegratingCommand(command) {
+		oldHead = e.headCommit(ctx, repo.Path)
+	}
 
-	cmd := exec.CommandContext(ctx, "git", strings.Fields(command)...)
-	cmd.Dir = repo.Path
+	// In autostash mode every pull uses the pull strategy, stashed or not
+	if autostash {
+		command = strings.Join(append([]string{"pull"}, e.pullOptions(args)...), " ")
+		result.Command = command
+	}
 
-	output, err := cmd.CombinedOutput()
-	result.Output = string(output)
-	result.Duration = time.Since(start)
+	switch {
+	case stashNeeded:
+		result = e.pullWithAutostash(ctx, repo, args, start)
+	case subcommand == "pull" && e.config.AllBranches:
+		// Handle special case for pull all branches
+		result = e.pullAllBranches(ctx, repo, e.pullOptions(args), start)
+	default:
+		// Execute the command with timeout
+		name, args := "git", strings.Fields(command)
+		if IsShellCommand(command) {
+			name, args = "sh", []string{"-c", strings.TrimPrefix(command, "!")}
+		}
+		output, stderr, err := e.runStreaming(ctx, repo, command, name, args...)
+		result.Output = output
+		result.Stderr = stderr
+		result.Duration = time.Since(start)
 
-	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			result.Error = fmt.Sprintf("Command timed out after %v", e.config.Timeout)
-		} else {
+		if err != nil {
 			result.Error = err.Error()
+			result.TimedOut = isTimeout(err)
+			result.Success = false
+		} else {
+			result.Success = true
 		}
-		result.Success = false
-	} else {
-		result.Success = true
 	}
 
+	e.recordChanges(ctx, result, oldHead)
 	return result
 }
 
-// ExecuteCommandOnRepositories executes a command on multiple repositories
-func (e *Executor) ExecuteCommandOnRepositories(repositories []*types.Repository, command string) []*types.ExecutionResult {
+// ExecuteCommandOnRepositories executes a command on multiple repositories.
+// The whole run is limited by ctx and the configured run deadline.
+func (e *Executor) ExecuteCommandOnRepositories(ctx context.Context, repositories []*types.Repository, command string) []*types.ExecutionResult {
+	if e.config.RunTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, e.config.RunTimeout, &TimeoutError{Scope: "Run", Timeout: e.config.RunTimeout})
+		defer cancel()
+	}
+
+	e.enqueue(repositories)
+	for _, repo := range repositories {
+		e.emit(ctx, Event{Type: EventQueued, Repository: repo, Command: command})
+	}
+
 	if !e.config.Parallel {
-		return e.executeSequentially(repositories, command)
+		return e.executeSequentially(ctx, repositories, command)
 	}
-	return e.executeInParallel(repositories, command)
+	return e.executeInParallel(ctx, repositories, command)
 }
 
 // executeSequentially executes commands one by one
-func (e *Executor) executeSequentially(repositories []*types.Repository, command string) []*types.ExecutionResult {
+func (e *Executor) executeSequentially(ctx context.Context, repositories []*types.Repository, command string) []*types.ExecutionResult {
 	results := make([]*types.ExecutionResult, 0, len(repositories))
+	failures := 0
 
 	for _, repo := range repositories {
-		if e.config.Verbose {
-			fmt.Printf("%s %s\n", colors.Info("Executing 'git "+command+"' in"), colors.Dim(repo.Path+"..."))
+		if reason := e.abortReason(ctx, failures); reason != "" {
+			results = append(results, e.abortedResult(ctx, repo, command, reason))
+			continue
 		}
-		
-		result := e.ExecuteCommand(repo, command)
+		if e.isCancelled(repo) {
+			results = append(results, e.abortedResult(ctx, repo, command, ErrCancelled.Error()))
+			continue
+		}
+
+		result := e.ExecuteCommand(ctx, repo, command)
 		results = append(results, result)
-		
-		if e.config.Verbose {
-			e.printResult(result)
+		if isFailure(result) {
+			failures++
 		}
 	}
 
 	return results
 }
 
+// workersKey is the context key of the worker slots of a parallel run
+type workersKey struct{}
+
 // executeInParallel executes commands in parallel with worker pool
-func (e *Executor) executeInParallel(repositories []*types.Repository, command string) []*types.ExecutionResult {
-	jobsCh := make(chan *types.Repository, len(repositories))
+func (e *Executor) executeInParallel(ctx context.Context, repositories []*types.Repository, command string) []*types.ExecutionResult {
 	resultsCh := make(chan *types.ExecutionResult, len(repositories))
 
-	// Start workers
+	// Track failures so queued work can be abandoned once the threshold is reached
+	var mu sync.Mutex
+	failures := 0
+	abortReason := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return e.abortReason(ctx, failures)
+	}
+
+	// Each repository runs once it gets one of the workers, in order. A
+	// repository waiting for a slot on its remote host gives its worker
+	// back meanwhile, so repositories on other hosts are not held up.
+	workers := make(chan struct{}, e.config.MaxWorkers)
+	ctx = context.WithValue(ctx, workersKey{}, workers)
+
 	var wg sync.WaitGroup
-	for i := 0; i < e.config.MaxWorkers; i++ {
+	for _, repo := range repositories {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for repo := range jobsCh {
-				if e.config.Verbose {
-					fmt.Printf("%s %s\n", colors.Info("Executing 'git "+command+"' in"), colors.Dim(repo.Path+"..."))
-				}
-				
-				result := e.ExecuteCommand(repo, command)
-				resultsCh <- result
-				
-				if e.config.Verbose {
-					e.printResult(result)
-				}
+			workers <- struct{}{}
+			defer func() { <-workers }()
+
+			if reason := abortReason(); reason != "" {
+				resultsCh <- e.abortedResult(ctx, repo, command, reason)
+				return
+			}
+			if e.isCancelled(repo) {
+				resultsCh <- e.abortedResult(ctx, repo, command, ErrCancelled.Error())
+				return
+			}
+
+			result := e.ExecuteCommand(ctx, repo, command)
+			if isFailure(result) {
+				mu.Lock()
+				failures++
+				mu.Unlock()
 			}
+			resultsCh <- result
 		}()
 	}
 
-	// Send jobs
-	go func() {
-		for _, repo := range repositories {
-			jobsCh <- repo
-		}
-		close(jobsCh)
-	}()
-
 	// Wait for workers to finish
 	go func() {
 		wg.Wait()
@@ -153,37 +289,239 @@ func (e *Executor) executeInParallel(repositories []*types.Repository, command s
 	return results
 }
 
-// isRepositoryDirty checks if repository has uncommitted changes
-func (e *Executor) isRepositoryDirty(repoPath string) (bool, error) {
-	cmd := exec.Command("git", "status", "--porcelain")
+// thresholdReached checks if the number of failures reached the configured limit
+func (e *Executor) thresholdReached(failures int) bool {
+	if e.config.FailFast {
+		return failures >= 1
+	}
+	return e.config.MaxFailures > 0 && failures >= e.config.MaxFailures
+}
+
+// abortReason returns why queued repositories should no longer be processed, or an empty string
+func (e *Executor) abortReason(ctx context.Context, failures int) string {
+	if ctx.Err() != nil {
+		return context.Cause(ctx).Error()
+	}
+	if e.thresholdReached(failures) {
+		return "Run aborted after reaching the failure threshold"
+	}
+	return ""
+}
+
+// abortedResult creates and reports the result for a repository that was not processed because the run was aborted
+func (e *Executor) abortedResult(ctx context.Context, repo *types.Repository, command, reason string) *types.ExecutionResult {
+	e.dequeue(repo)
+	result := &types.ExecutionResult{
+		Repository: repo,
+		Command:    command,
+		Success:    false,
+		Error:      reason + " (skipped)",
+		Skipped:    true,
+		Aborted:    true,
+	}
+	e.emit(ctx, Event{Type: EventFinished, Repository: repo, Command: command, Result: result})
+	return result
+}
+
+// isFailure checks if the result counts towards the failure threshold
+func isFailure(result *types.ExecutionResult) bool {
+	return !result.Success && !result.Skipped
+}
+
+// repositoryDirtyStates returns the configured dirty states that apply to the
+// repository, and whether a rebase is in progress whether or not that state
+// is configured
+func (e *Executor) repositoryDirtyStates(ctx context.Context, repoPath string) ([]string, bool, error) {
+	ctx, cancel := e.commandContext(ctx)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
 	cmd.Dir = repoPath
 
 	output, err := cmd.Output()
 	if err != nil {
-		return false, err
+		return nil, false, commandError(ctx, err)
+	}
+
+	found := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) < 2 {
+			continue
+		}
+		if line[:2] == "??" {
+			found[types.DirtyUntracked] = true
+			continue
+		}
+		if line[0] != ' ' {
+			found[types.DirtyStaged] = true
+		}
+		if line[1] != ' ' {
+			found[types.DirtyUnstaged] = true
+		}
+	}
+	found[types.DirtyRebase] = e.isRebaseInProgress(ctx, repoPath)
+
+	var states []string
+	for _, state := range e.config.DirtyStates {
+		if found[state] {
+			states = append(states, state)
+		}
+	}
+	return states, found[types.DirtyRebase], nil
+}
+
+// containsState checks if state is in states
+func containsState(states []string, state string) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// describeDirtyStates returns a human readable description of dirty states
+func describeDirtyStates(states []string) string {
+	descriptions := map[string]string{
+		types.DirtyStaged:    "staged changes",
+		types.DirtyUnstaged:  "unstaged changes",
+		types.DirtyUntracked: "untracked files",
+		types.DirtyRebase:    "a rebase in progress",
 	}
 
-	return len(strings.TrimSpace(string(output))) > 0, nil
+	parts := make([]string, 0, len(states))
+	for _, state := range states {
+		parts = append(parts, descriptions[state])
+	}
+	return strings.Join(parts, ", ")
 }
 
-// pullAllBranches pulls all branches in the repository
-func (e *Executor) pullAllBranches(repo *types.Repository, start time.Time) *types.ExecutionResult {
+// runGit runs a git command in the repository with the configured timeout
+func (e *Executor) runGit(ctx context.Context, repoPath string, args ...string) (string, error) {
+	output, _, err := e.runGitCapture(ctx, repoPath, args...)
+	return output, err
+}
+
+// runGitCapture runs a git command and returns both its combined output and its stderr
+func (e *Executor) runGitCapture(ctx context.Context, repoPath string, args ...string) (string, string, error) {
+	return e.runTee(ctx, repoPath, nil, nil, "git", args...)
+}
+
+// runStreaming runs a program like runGitCapture, also reporting each
+// output line of the command as an event
+func (e *Executor) runStreaming(ctx context.Context, repo *types.Repository, command, name string, args ...string) (string, string, error) {
+	if !e.hasEventHandlers(ctx) {
+		return e.runTee(ctx, repo.Path, nil, nil, name, args...)
+	}
+
+	stdoutLines := &lineWriter{emit: func(line string) { e.outputLine(ctx, repo, command, line, false) }}
+	stderrLines := &lineWriter{emit: func(line string) { e.outputLine(ctx, repo, command, line, true) }}
+	defer stdoutLines.Flush()
+	defer stderrLines.Flush()
+
+	return e.runTee(ctx, repo.Path, stdoutLines, stderrLines, name, args...)
+}
+
+// runTee runs a program, copying stdout and stderr to the optional extra writers
+func (e *Executor) runTee(ctx context.Context, repoPath string, stdoutTee, stderrTee io.Writer, name string, args ...string) (string, string, error) {
+	ctx, cancel := e.commandContext(ctx)
+	defer cancel()
+
+	var combined lockedBuffer
+	var stderr bytes.Buffer
+
+	stdoutWriters := []io.Writer{&combined}
+	stderrWriters := []io.Writer{&combined, &stderr}
+	if stdoutTee != nil {
+		stdoutWriters = append(stdoutWriters, stdoutTee)
+	}
+	if stderrTee != nil {
+		stderrWriters = append(stderrWriters, stderrTee)
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = repoPath
+	cmd.WaitDelay = waitDelay
+	cmd.Stdout = io.MultiWriter(stdoutWriters...)
+	cmd.Stderr = io.MultiWriter(stderrWriters...)
+
+	err := cmd.Run()
+	if err != nil {
+		return combined.String(), stderr.String(), commandError(ctx, err)
+	}
+	return combined.String(), stderr.String(), nil
+}
+
+// lockedBuffer is a bytes.Buffer safe for concurrent writes from stdout and stderr
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// commandContext derives the context for a single git command from ctx and the configured timeout
+func (e *Executor) commandContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, e.config.Timeout, &TimeoutError{Scope: "Command", Timeout: e.config.Timeout})
+}
+
+// TimeoutError reports that a deadline stopped a command
+type TimeoutError struct {
+	Scope   string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%s timed out after %v", e.Scope, e.Timeout)
+}
+
+// isTimeout checks if err was caused by a deadline
+func isTimeout(err error) bool {
+	var timeoutErr *TimeoutError
+	return errors.As(err, &timeoutErr)
+}
+
+// commandError reports which deadline stopped a command, if any
+func commandError(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, context.Canceled) {
+		return cause
+	}
+	return err
+}
+
+// pullAllBranches pulls all branches in the repository.
+// All branches share the repository deadline carried by ctx.
+func (e *Executor) pullAllBranches(ctx context.Context, repo *types.Repository, options []string, start time.Time) *types.ExecutionResult {
 	result := &types.ExecutionResult{
 		Repository: repo,
-		Command:    "pull --all",
+		Command:    strings.Join(append([]string{"pull", "--all"}, options...), " "),
 		Success:    false,
 	}
 
 	// Get all remote branches
-	ctx, cancel := context.WithTimeout(context.Background(), e.config.Timeout)
+	branchCtx, cancel := e.commandContext(ctx)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "git", "branch", "-r")
+	cmd := exec.CommandContext(branchCtx, "git", "branch", "-r")
 	cmd.Dir = repo.Path
 
 	output, err := cmd.Output()
 	if err != nil {
+		err = commandError(branchCtx, err)
 		result.Error = fmt.Sprintf("Error getting remote branches: %v", err)
+		result.TimedOut = isTimeout(err)
 		result.Duration = time.Since(start)
 		return result
 	}
@@ -202,17 +540,13 @@ func (e *Executor) pullAllBranches(repo *types.Repository, start time.Time) *typ
 	// Pull each branch
 	var outputs []string
 	for _, branch := range branches {
-		ctx, cancel := context.WithTimeout(context.Background(), e.config.Timeout)
-		cmd := exec.CommandContext(ctx, "git", "pull", "origin", branch)
-		cmd.Dir = repo.Path
-
-		branchOutput, err := cmd.CombinedOutput()
-		outputs = append(outputs, fmt.Sprintf("Branch %s: %s", branch, string(branchOutput)))
-		
-		cancel()
+		pullArgs := append(append([]string{"pull"}, options...), "origin", branch)
+		branchOutput, err := e.runGit(ctx, repo.Path, pullArgs...)
+		outputs = append(outputs, fmt.Sprintf("Branch %s: %s", branch, branchOutput))
 		
 		if err != nil {
 			result.Error = fmt.Sprintf("Error pulling branch %s: %v", branch, err)
+			result.TimedOut = isTimeout(err)
 			result.Output = strings.Join(outputs, "\n")
 			result.Duration = time.Since(start)
 			return result
@@ -224,35 +558,3 @@ func (e *Executor) pullAllBranches(repo *types.Repository, start time.Time) *typ
 	result.Duration = time.Since(start)
 	return result
 }
-
-// printResult prints the execution result with colors
-func (e *Executor) printResult(result *types.ExecutionResult) {
-	var icon, status string
-	if result.Success {
-		icon = colors.SuccessIcon()
-		status = colors.Success(result.Repository.Name)
-	} else {
-		icon = colors.ErrorIcon()
-		status = colors.Error(result.Repository.Name)
-	}
-	
-	duration := colors.Dim(fmt.Sprintf("(%v)", result.Duration))
-	fmt.Printf("%s %s %s\n", icon, status, duration)
-	
-	if result.Error != "" {
-		if strings.Contains(result.Error, "skipped") {
-			fmt.Printf("  %s %s\n", colors.WarningIcon(), colors.Warning(result.Error))
-		} else if strings.Contains(result.Error, "timed out") {
-			fmt.Printf("  %s %s\n", colors.WarningIcon(), colors.Warning(result.Error))
-		} else {
-			fmt.Printf("  %s %s\n", colors.ErrorIcon(), colors.Error(result.Error))
-		}
-	}
-	
-	if result.Output != "" && e.config.Verbose {
-		output := strings.TrimSpace(result.Output)
-		if output != "" {
-			fmt.Printf("  %s %s\n", colors.InfoIcon(), colors.Dim(output))
-		}
-	}
-}
\ No newline at end of file
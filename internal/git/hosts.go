@@ -0,0 +1,129 @@
+package git
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// defaultRemote is the remote whose URL determines the repository host
+const defaultRemote = "origin"
+
+// RemoteHost extracts the host name from a git remote URL.
+// It returns an empty string for local paths and file URLs.
+func RemoteHost(remoteURL string) string {
+	remoteURL = strings.TrimSpace(remoteURL)
+	if remoteURL == "" {
+		return ""
+	}
+
+	// URL syntax, e.g. https://host/repo.git or ssh://user@host:22/repo.git
+	if strings.Contains(remoteURL, "://") {
+		parsed, err := url.Parse(remoteURL)
+		if err != nil || parsed.Scheme == "file" {
+			return ""
+		}
+		return strings.ToLower(parsed.Hostname())
+	}
+
+	// scp-like syntax, e.g. git@host:repo.git
+	colon := strings.Index(remoteURL, ":")
+	if colon <= 0 || strings.Contains(remoteURL[:colon], "/") {
+		return ""
+	}
+	host := remoteURL[:colon]
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	return strings.ToLower(host)
+}
+
+// repositoryHost resolves the host of the repository's remote
+func (e *Executor) repositoryHost(ctx context.Context, repoPath string) string {
+	return RemoteHost(e.RemoteURL(ctx, repoPath))
+}
+
+// RemoteURL returns the URL of the repository's origin remote, or of its
+// first remote if there is no origin. It returns an empty string for
+// repositories without remotes.
+func (e *Executor) RemoteURL(ctx context.Context, repoPath string) string {
+	output, err := e.runGit(ctx, repoPath, "remote")
+	if err != nil {
+		return ""
+	}
+
+	remotes := strings.Fields(output)
+	if len(remotes) == 0 {
+		return ""
+	}
+	remote := remotes[0]
+	for _, name := range remotes {
+		if name == defaultRemote {
+			remote = name
+			break
+		}
+	}
+
+	output, err = e.runGit(ctx, repoPath, "remote", "get-url", remote)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(output)
+}
+
+// hostLimit returns the concurrency limit configured for host, or 0 if unlimited
+func (e *Executor) hostLimit(host string) int {
+	for _, pattern := range e.hostPatterns {
+		if matched, _ := filepath.Match(pattern, host); matched {
+			return e.config.HostLimits[pattern]
+		}
+	}
+	return 0
+}
+
+// acquireHostSlot waits for a free slot on the repository's remote host when
+// the command is network-bound and the host has a concurrency limit.
+// The returned function releases the slot. In a parallel run, the worker of
+// the repository is free for other repositories while it waits.
+func (e *Executor) acquireHostSlot(ctx context.Context, repoPath, command string) (func(), error) {
+	release := func() {}
+	if len(e.config.HostLimits) == 0 || !IsNetworkCommand(command) {
+		return release, nil
+	}
+
+	host := e.repositoryHost(ctx, repoPath)
+	limit := e.hostLimit(host)
+	if host == "" || limit <= 0 {
+		return release, nil
+	}
+
+	e.hostMu.Lock()
+	slots, ok := e.hostSlots[host]
+	if !ok {
+		slots = make(chan struct{}, limit)
+		e.hostSlots[host] = slots
+	}
+	e.hostMu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	default:
+	}
+
+	// The host is saturated: give the worker back while waiting, so
+	// repositories on other hosts can run, and take one again afterwards
+	workers, _ := ctx.Value(workersKey{}).(chan struct{})
+	if workers != nil {
+		<-workers
+		defer func() { workers <- struct{}{} }()
+	}
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return release, context.Cause(ctx)
+	}
+}
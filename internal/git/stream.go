@@ -0,0 +1,48 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// outputLine reports a line of command output
+func (e *Executor) outputLine(ctx context.Context, repo *types.Repository, command, line string, stderr bool) {
+	e.emit(ctx, Event{Type: EventOutputLine, Repository: repo, Command: command, Line: line, Stderr: stderr})
+}
+
+// lineWriter splits written data into lines and passes each complete line to emit
+type lineWriter struct {
+	mu   sync.Mutex
+	buf  bytes.Buffer
+	emit func(line string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		line := string(bytes.TrimRight(w.buf.Next(i+1), "\r\n"))
+		w.emit(line)
+	}
+	return len(p), nil
+}
+
+// Flush emits any remaining partial line
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.emit(w.buf.String())
+		w.buf.Reset()
+	}
+}
@@ -0,0 +1,99 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/report"
+)
+
+// Thresholds for reporting a duration change as significant
+const (
+	durationChangeRatio = 0.5
+	durationChangeMin   = 500 * time.Millisecond
+)
+
+// RepositoryChange compares a repository between two runs
+type RepositoryChange struct {
+	Name   string
+	Path   string
+	Before RepositoryRecord
+	After  RepositoryRecord
+}
+
+// Diff lists the differences between two runs
+type Diff struct {
+	NewlyFailed     []RepositoryChange
+	Recovered       []RepositoryChange
+	DurationChanged []RepositoryChange
+	Added           []RepositoryRecord
+	Removed         []RepositoryRecord
+}
+
+// Compare computes the differences from run a to run b, matching repositories by path
+func Compare(a, b *RunRecord) *Diff {
+	before := make(map[string]RepositoryRecord, len(a.Results))
+	for _, result := range a.Results {
+		before[result.Path] = result
+	}
+
+	diff := &Diff{}
+	seen := make(map[string]bool, len(b.Results))
+	for _, after := range b.Results {
+		seen[after.Path] = true
+		prev, ok := before[after.Path]
+		if !ok {
+			diff.Added = append(diff.Added, after)
+			continue
+		}
+
+		change := RepositoryChange{Name: after.Name, Path: after.Path, Before: prev, After: after}
+		prevFailed := prev.Status == report.StatusFailed
+		failed := after.Status == report.StatusFailed
+		switch {
+		case failed && !prevFailed:
+			diff.NewlyFailed = append(diff.NewlyFailed, change)
+		case prevFailed && !failed:
+			diff.Recovered = append(diff.Recovered, change)
+		case !failed && significantChange(prev.Duration, after.Duration):
+			diff.DurationChanged = append(diff.DurationChanged, change)
+		}
+	}
+
+	for _, result := range a.Results {
+		if !seen[result.Path] {
+			diff.Removed = append(diff.Removed, result)
+		}
+	}
+
+	sortChanges(diff.NewlyFailed)
+	sortChanges(diff.Recovered)
+	sortChanges(diff.DurationChanged)
+	sortRecords(diff.Added)
+	sortRecords(diff.Removed)
+	return diff
+}
+
+// significantChange checks if a duration changed enough to be worth reporting
+func significantChange(before, after time.Duration) bool {
+	delta := after - before
+	if delta < 0 {
+		delta = -delta
+	}
+	if delta < durationChangeMin {
+		return false
+	}
+	return before == 0 || float64(delta)/float64(before) >= durationChangeRatio
+}
+
+func sortChanges(changes []RepositoryChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+}
+
+func sortRecords(records []RepositoryRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Name < records[j].Name
+	})
+}
@@ -0,0 +1,193 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/report"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// RunRecord is a persisted run
+type RunRecord struct {
+	ID       string             `json:"id"`
+	Command  string             `json:"command"`
+	RootPath string             `json:"root_path"`
+	Started  time.Time          `json:"started"`
+	Duration time.Duration      `json:"duration"`
+	Results  []RepositoryRecord `json:"results"`
+}
+
+// RepositoryRecord is the persisted result of a single repository
+type RepositoryRecord struct {
+	Name     string        `json:"name"`
+	Path     string        `json:"path"`
+	Status   string        `json:"status"`
+	Error    string        `json:"error,omitempty"`
+	Aborted  bool          `json:"aborted,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Count returns how many repositories in the run have the given status
+func (r *RunRecord) Count(status string) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// FailedRepositories returns the repositories that failed or were never
+// started because the run was aborted
+func (r *RunRecord) FailedRepositories() []*types.Repository {
+	var repositories []*types.Repository
+	for _, result := range r.Results {
+		if result.Status == report.StatusFailed || result.Aborted {
+			repositories = append(repositories, &types.Repository{
+				Path: result.Path,
+				Name: result.Name,
+			})
+		}
+	}
+	return repositories
+}
+
+// NewRecord creates a run record from a completed run
+func NewRecord(run *report.Run) *RunRecord {
+	record := &RunRecord{
+		ID:       run.Started.Format("20060102-150405.000"),
+		Command:  run.Command,
+		RootPath: absPath(run.RootPath),
+		Started:  run.Started,
+		Duration: run.Duration,
+		Results:  make([]RepositoryRecord, 0, len(run.Results)),
+	}
+
+	for _, result := range run.Results {
+		record.Results = append(record.Results, RepositoryRecord{
+			Name:     result.Repository.Name,
+			Path:     absPath(result.Repository.Path),
+			Status:   report.Status(result),
+			Error:    result.Error,
+			Aborted:  result.Aborted,
+			Duration: result.Duration,
+		})
+	}
+	return record
+}
+
+// InWorkspace returns the runs whose root path is root
+func InWorkspace(records []*RunRecord, root string) []*RunRecord {
+	root = absPath(root)
+	var filtered []*RunRecord
+	for _, record := range records {
+		if record.RootPath == root {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered
+}
+
+// absPath returns the absolute form of path, or path itself if it cannot be
+// resolved. Paths are stored absolute so runs from different working
+// directories can be told apart and compared.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// DefaultPath returns the history file location in the user data directory
+func DefaultPath() (string, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataDir, "rgp", "history.jsonl"), nil
+}
+
+// Append adds a run record to the history file, creating it if needed
+func Append(path string, record *RunRecord) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Load reads all run records from the history file, oldest first.
+// A missing history file is not an error.
+func Load(path string) ([]*RunRecord, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []*RunRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		record := &RunRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// Find returns the run with the given ID. The IDs "last" and "previous"
+// refer to the most recent and the second most recent runs.
+func Find(records []*RunRecord, id string) (*RunRecord, error) {
+	switch id {
+	case "last":
+		if len(records) > 0 {
+			return records[len(records)-1], nil
+		}
+	case "previous":
+		if len(records) > 1 {
+			return records[len(records)-2], nil
+		}
+	default:
+		for _, record := range records {
+			if record.ID == id {
+				return record, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("run '%s' not found in history", id)
+}
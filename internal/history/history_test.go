@@ -0,0 +1,158 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/report"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// names returns the repository names of changes
+func names(changes []RepositoryChange) []string {
+	var result []string
+	for _, change := range changes {
+		result = append(result, change.Name)
+	}
+	return result
+}
+
+// recordNames returns the repository names of records
+func recordNames(records []RepositoryRecord) []string {
+	var result []string
+	for _, record := range records {
+		result = append(result, record.Name)
+	}
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	record := func(name, status string, duration time.Duration) RepositoryRecord {
+		return RepositoryRecord{Name: name, Path: "/work/" + name, Status: status, Duration: duration}
+	}
+	a := &RunRecord{ID: "a", Results: []RepositoryRecord{
+		record("broken", report.StatusSucceeded, time.Second),
+		record("fixed", report.StatusFailed, time.Second),
+		record("slower", report.StatusSucceeded, time.Second),
+		record("steady", report.StatusSucceeded, time.Second),
+		record("removed", report.StatusSucceeded, time.Second),
+	}}
+	b := &RunRecord{ID: "b", Results: []RepositoryRecord{
+		record("broken", report.StatusFailed, time.Second),
+		record("fixed", report.StatusSucceeded, time.Second),
+		record("slower", report.StatusSucceeded, 3*time.Second),
+		record("steady", report.StatusSucceeded, 1100*time.Millisecond),
+		record("added", report.StatusSucceeded, time.Second),
+	}}
+
+	diff := Compare(a, b)
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"newly failed", names(diff.NewlyFailed), []string{"broken"}},
+		{"recovered", names(diff.Recovered), []string{"fixed"}},
+		{"duration changed", names(diff.DurationChanged), []string{"slower"}},
+		{"added", recordNames(diff.Added), []string{"added"}},
+		{"removed", recordNames(diff.Removed), []string{"removed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareMatchesByPath(t *testing.T) {
+	// Two repositories with the same name in different directories
+	a := &RunRecord{Results: []RepositoryRecord{
+		{Name: "api", Path: "/work/team-a/api", Status: report.StatusFailed},
+		{Name: "api", Path: "/work/team-b/api", Status: report.StatusSucceeded},
+	}}
+	b := &RunRecord{Results: []RepositoryRecord{
+		{Name: "api", Path: "/work/team-a/api", Status: report.StatusFailed},
+		{Name: "api", Path: "/work/team-b/api", Status: report.StatusSucceeded},
+	}}
+
+	diff := Compare(a, b)
+	if len(diff.NewlyFailed) != 0 || len(diff.Recovered) != 0 || len(diff.Added) != 0 || len(diff.Removed) != 0 {
+		t.Errorf("Compare of identical runs = %+v, want no differences", diff)
+	}
+}
+
+func TestFind(t *testing.T) {
+	records := []*RunRecord{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+
+	tests := []struct {
+		id      string
+		records []*RunRecord
+		want    string
+		wantErr bool
+	}{
+		{id: "last", records: records, want: "3"},
+		{id: "previous", records: records, want: "2"},
+		{id: "1", records: records, want: "1"},
+		{id: "4", records: records, wantErr: true},
+		{id: "previous", records: records[:1], wantErr: true},
+		{id: "last", records: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			record, err := Find(tt.records, tt.id)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Find(%q) = %s, want an error", tt.id, record.ID)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Find(%q): %v", tt.id, err)
+			}
+			if record.ID != tt.want {
+				t.Errorf("Find(%q) = %s, want %s", tt.id, record.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestInWorkspace(t *testing.T) {
+	abs, err := filepath.Abs("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := []*RunRecord{{ID: "1", RootPath: abs}, {ID: "2", RootPath: "/elsewhere"}, {ID: "3", RootPath: abs}}
+
+	var ids []string
+	for _, record := range InWorkspace(records, "work") {
+		ids = append(ids, record.ID)
+	}
+	if want := []string{"1", "3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("InWorkspace(records, \"work\") = %v, want %v", ids, want)
+	}
+}
+
+func TestNewRecordStoresAbsolutePaths(t *testing.T) {
+	run := &report.Run{
+		Command:  "pull",
+		RootPath: "work",
+		Started:  time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC),
+		Results: []*types.ExecutionResult{
+			{Repository: &types.Repository{Name: "api", Path: filepath.Join("work", "api")}, Success: true},
+		},
+	}
+
+	record := NewRecord(run)
+	if record.ID != "20250101-093000.000" {
+		t.Errorf("ID = %s, want 20250101-093000.000", record.ID)
+	}
+	if !filepath.IsAbs(record.RootPath) || !filepath.IsAbs(record.Results[0].Path) {
+		t.Errorf("paths not absolute: root %s, repository %s", record.RootPath, record.Results[0].Path)
+	}
+}
@@ -0,0 +1,167 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"os"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/terminal"
+)
+
+// keyPress is a key read from the terminal, or the error that stopped reading
+type keyPress struct {
+	key  terminal.Key
+	char rune
+	err  error
+}
+
+// action is what the main loop must do after a key press
+type action int
+
+const (
+	actionNone action = iota
+	actionQuit
+	actionRetry
+)
+
+// readKeys sends the keys pressed on the terminal to keys until reading
+// fails or ctx is done. It reads from its own handle on the terminal, which
+// is closed once ctx is done so a pending read does not consume a key
+// meant for whatever runs after the monitor.
+func readKeys(ctx context.Context, keys chan<- keyPress) {
+	input := os.Stdin
+	if tty, err := os.Open("/dev/tty"); err == nil {
+		defer tty.Close()
+		stop := context.AfterFunc(ctx, func() { tty.Close() })
+		defer stop()
+		input = tty
+	}
+
+	in := bufio.NewReader(input)
+	for {
+		key, char, err := terminal.ReadKey(in)
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case keys <- keyPress{key: key, char: char, err: err}:
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleKey applies a key press to the view
+func (m *monitor) handleKey(k keyPress, rows int) action {
+	m.message = ""
+	page := rows - headerLines
+	if page < 1 {
+		page = 1
+	}
+
+	if k.key == terminal.KeyCtrlC {
+		return actionQuit
+	}
+
+	if m.viewing != nil {
+		switch {
+		case k.key == terminal.KeyEscape || k.key == terminal.KeyBackspace || k.key == terminal.KeyLeft || k.char == 'q':
+			m.viewing = nil
+		case k.key == terminal.KeyUp || k.char == 'k':
+			m.scrollOutput(-1, page)
+		case k.key == terminal.KeyDown || k.char == 'j':
+			m.scrollOutput(1, page)
+		case k.key == terminal.KeyPageUp:
+			m.scrollOutput(-page, page)
+		case k.key == terminal.KeyPageDown || k.char == ' ':
+			m.scrollOutput(page, page)
+		case k.key == terminal.KeyHome || k.char == 'g':
+			m.scroll = 0
+			m.followOutput = false
+		case k.key == terminal.KeyEnd || k.char == 'G':
+			m.followOutput = true
+		}
+		return actionNone
+	}
+
+	switch {
+	case k.char == 'q' || k.key == terminal.KeyEscape:
+		return actionQuit
+	case k.key == terminal.KeyUp || k.char == 'k':
+		m.move(-1)
+	case k.key == terminal.KeyDown || k.char == 'j':
+		m.move(1)
+	case k.key == terminal.KeyPageUp:
+		m.move(-page)
+	case k.key == terminal.KeyPageDown:
+		m.move(page)
+	case k.key == terminal.KeyHome || k.char == 'g':
+		m.move(-len(m.entries))
+	case k.key == terminal.KeyEnd || k.char == 'G':
+		m.move(len(m.entries))
+	case k.key == terminal.KeyEnter || k.key == terminal.KeyRight:
+		if len(m.entries) > 0 {
+			m.viewing = m.entries[m.cursor]
+			m.followOutput = true
+		}
+	case k.char == 'c':
+		if e := m.current(); e != nil && e.result == nil {
+			m.executor.Cancel(e.repo)
+			m.message = "Cancelling " + e.repo.Name
+		}
+	case k.char == 'r':
+		if e := m.current(); e != nil && e.retryable() {
+			e.state = stateQueued
+			e.result = nil
+			m.message = "Retrying " + e.repo.Name
+			return actionRetry
+		}
+	}
+	return actionNone
+}
+
+// current returns the highlighted repository
+func (m *monitor) current() *entry {
+	if len(m.entries) == 0 {
+		return nil
+	}
+	return m.entries[m.cursor]
+}
+
+// move moves the cursor by delta, staying within the list
+func (m *monitor) move(delta int) {
+	m.cursor += delta
+	if m.cursor >= len(m.entries) {
+		m.cursor = len(m.entries) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// scrollOutput scrolls the output view by delta lines
+func (m *monitor) scrollOutput(delta, page int) {
+	if m.followOutput {
+		m.scroll = m.maxScroll(page)
+	}
+	m.scroll += delta
+	m.followOutput = false
+	if m.scroll >= m.maxScroll(page) {
+		m.scroll = m.maxScroll(page)
+		m.followOutput = true
+	}
+	if m.scroll < 0 {
+		m.scroll = 0
+	}
+}
+
+// maxScroll returns the scroll position showing the last page of output
+func (m *monitor) maxScroll(page int) int {
+	if m.viewing == nil || len(m.viewing.output) <= page {
+		return 0
+	}
+	return len(m.viewing.output) - page
+}
@@ -0,0 +1,230 @@
+// Package monitor implements the full-screen view of a running command,
+// driven by the events of the executor.
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/report"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/terminal"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// Repository states shown before a result is available
+const (
+	stateQueued  = "queued"
+	stateRunning = "running"
+)
+
+// maxOutputLines bounds the output kept for each repository
+const maxOutputLines = 5000
+
+// refreshInterval is how often elapsed times are redrawn
+const refreshInterval = 250 * time.Millisecond
+
+// headerLines is the number of lines drawn above the list or the output
+const headerLines = 3
+
+// errRunCancelled is the cause of a run stopped by quitting the monitor
+var errRunCancelled = errors.New("Run cancelled by user")
+
+// entry is the state of a repository in the monitor
+type entry struct {
+	repo    *types.Repository
+	state   string
+	started time.Time
+	result  *types.ExecutionResult
+	output  []string
+}
+
+// monitor holds the state of the full-screen view
+type monitor struct {
+	executor *git.Executor
+	command  string
+	started  time.Time
+
+	entries []*entry
+	byPath  map[string]*entry
+
+	cursor int
+	offset int
+
+	// Output view of the selected repository, if open
+	viewing      *entry
+	scroll       int
+	followOutput bool
+
+	runDone  bool
+	retrying int
+	message  string
+}
+
+// Run executes the command on the repositories while showing their
+// progress full screen, and returns the latest result of every repository
+// once the user quits. Failed repositories can be retried from the view,
+// in which case the retry replaces the earlier result.
+//
+// Keys: up/down to move, enter to show a repository's output, c to cancel
+// the highlighted repository, r to retry it, q to quit (cancelling the run
+// and any retries still going, which are shown until they return).
+func Run(ctx context.Context, executor *git.Executor, repositories []*types.Repository, command string) ([]*types.ExecutionResult, error) {
+	m := &monitor{
+		executor: executor,
+		command:  command,
+		started:  time.Now(),
+		byPath:   make(map[string]*entry, len(repositories)),
+	}
+	for _, repo := range repositories {
+		e := &entry{repo: repo, state: stateQueued}
+		m.entries = append(m.entries, e)
+		m.byPath[repo.Path] = e
+	}
+
+	restore, err := terminal.MakeRaw()
+	if err != nil {
+		return nil, err
+	}
+	out := os.Stderr
+	fmt.Fprint(out, terminal.EnterAltScreen+terminal.HideCursor)
+	defer func() {
+		fmt.Fprint(out, terminal.ShowCursor+terminal.ExitAltScreen)
+		restore()
+	}()
+
+	events := make(chan git.Event, 1024)
+	ctx = git.WithEventHandler(ctx, func(event git.Event) { events <- event })
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// Run the command and any retries in the background
+	runDone := make(chan struct{})
+	go func() {
+		executor.ExecuteCommandOnRepositories(ctx, repositories, command)
+		close(runDone)
+	}()
+	retryDone := make(chan struct{})
+
+	// Stop reading keys before the terminal is restored
+	keysCtx, stopKeys := context.WithCancel(context.Background())
+	defer stopKeys()
+	keys := make(chan keyPress)
+	go readKeys(keysCtx, keys)
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	quitting := false
+	for {
+		rows, cols := terminal.Size()
+		if err := m.render(rows, cols).Flush(out); err != nil {
+			return nil, err
+		}
+
+		if quitting && m.runDone && m.retrying == 0 {
+			break
+		}
+
+		select {
+		case event := <-events:
+			m.handleEvent(event)
+		case <-runDone:
+			m.runDone = true
+			runDone = nil
+		case <-retryDone:
+			m.retrying--
+		case <-ticker.C:
+		case key := <-keys:
+			if key.err != nil {
+				quitting = true
+				cancel(errRunCancelled)
+				keys = nil
+				continue
+			}
+			switch m.handleKey(key, rows) {
+			case actionQuit:
+				// Cancel the run and the retries, and keep showing them
+				// until they return
+				quitting = true
+				if !m.runDone || m.retrying > 0 {
+					m.message = "Cancelling the run..."
+				}
+				cancel(errRunCancelled)
+			case actionRetry:
+				repo := m.entries[m.cursor].repo
+				m.retrying++
+				go func() {
+					executor.Retry(ctx, repo, command)
+					retryDone <- struct{}{}
+				}()
+			}
+		}
+	}
+
+	// Apply the events emitted before the last command returned
+	for len(events) > 0 {
+		m.handleEvent(<-events)
+	}
+
+	results := make([]*types.ExecutionResult, 0, len(m.entries))
+	for _, e := range m.entries {
+		if e.result != nil {
+			results = append(results, e.result)
+		}
+	}
+	return results, nil
+}
+
+// handleEvent updates the state of a repository from an executor event
+func (m *monitor) handleEvent(event git.Event) {
+	e := m.byPath[event.Repository.Path]
+	if e == nil {
+		return
+	}
+
+	switch event.Type {
+	case git.EventQueued, git.EventRetrying:
+		e.state = stateQueued
+		e.result = nil
+	case git.EventStarted:
+		e.state = stateRunning
+		e.started = event.Time
+		e.result = nil
+		e.output = nil
+	case git.EventOutputLine:
+		e.output = append(e.output, event.Line)
+		if len(e.output) > maxOutputLines {
+			e.output = e.output[len(e.output)-maxOutputLines:]
+		}
+	case git.EventFinished:
+		e.result = event.Result
+		e.state = report.Status(event.Result)
+		if len(e.output) == 0 && event.Result.Output != "" {
+			e.output = splitLines(event.Result.Output)
+		}
+		if event.Result.Error != "" {
+			e.output = append(e.output, event.Result.Error)
+		}
+	}
+}
+
+// elapsed returns how long the repository has been running, or how long it took
+func (e *entry) elapsed() time.Duration {
+	switch {
+	case e.result != nil:
+		return e.result.Duration
+	case e.state == stateRunning:
+		return time.Since(e.started)
+	}
+	return 0
+}
+
+// retryable checks if the repository can be run again
+func (e *entry) retryable() bool {
+	return e.result != nil && !e.result.Success
+}
@@ -0,0 +1,119 @@
+package monitor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/report"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/terminal"
+)
+
+// stateWidth is the width of the state column
+const stateWidth = 10
+
+// render draws the current view for a terminal of the given size
+func (m *monitor) render(rows, cols int) *terminal.Screen {
+	if m.viewing != nil {
+		return m.renderOutput(rows, cols)
+	}
+	return m.renderList(rows, cols)
+}
+
+// renderList draws the list of repositories with their state
+func (m *monitor) renderList(rows, cols int) *terminal.Screen {
+	counts := make(map[string]int)
+	for _, e := range m.entries {
+		counts[e.state]++
+	}
+	done := len(m.entries) - counts[stateQueued] - counts[stateRunning]
+
+	status := fmt.Sprintf("%d/%d done, %d running, %d failed, %d skipped",
+		done, len(m.entries), counts[stateRunning], counts[report.StatusFailed], counts[report.StatusSkipped])
+	if m.runDone {
+		status += ", finished"
+	}
+
+	screen := &terminal.Screen{}
+	screen.Line(colors.Bold(terminal.Truncate(fmt.Sprintf("rgp: %s  (%v)", git.DisplayCommand(m.command), time.Since(m.started).Round(time.Second)), cols)))
+	screen.Line(terminal.Truncate(status, cols))
+	help := "up/down move, enter output, c cancel, r retry, q quit"
+	if m.message != "" {
+		help = m.message
+	}
+	screen.Line(colors.Dim(terminal.Truncate(help, cols)))
+
+	// Scroll to keep the cursor on screen
+	height := rows - headerLines
+	if height < 1 {
+		height = 1
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+height {
+		m.offset = m.cursor - height + 1
+	}
+
+	for i := m.offset; i < len(m.entries) && i < m.offset+height; i++ {
+		e := m.entries[i]
+		elapsed := ""
+		if d := e.elapsed(); d > 0 {
+			elapsed = d.Round(100 * time.Millisecond).String()
+		}
+
+		state := fmt.Sprintf("%-*s", stateWidth, e.state)
+		name := terminal.Truncate(e.repo.Name, cols-stateWidth-14)
+		if i == m.cursor {
+			line := terminal.Truncate(fmt.Sprintf("> %s %s %s", state, name, elapsed), cols)
+			screen.Line(terminal.Reverse + line + terminal.Reset)
+			continue
+		}
+		screen.Line("  " + styleState(e.state, state) + " " + name + " " + colors.Dim(elapsed))
+	}
+	return screen
+}
+
+// renderOutput draws the output of the repository being viewed
+func (m *monitor) renderOutput(rows, cols int) *terminal.Screen {
+	e := m.viewing
+	page := rows - headerLines
+	if page < 1 {
+		page = 1
+	}
+	if m.followOutput {
+		m.scroll = m.maxScroll(page)
+	}
+
+	screen := &terminal.Screen{}
+	screen.Line(colors.Bold(terminal.Truncate(fmt.Sprintf("%s  %s", e.repo.Name, e.repo.Path), cols)))
+	screen.Line(styleState(e.state, e.state) + " " + colors.Dim(e.elapsed().Round(100*time.Millisecond).String()))
+	screen.Line(colors.Dim(terminal.Truncate("up/down scroll, G follow, esc back", cols)))
+
+	for i := m.scroll; i < len(e.output) && i < m.scroll+page; i++ {
+		screen.Line(terminal.Truncate(strings.ReplaceAll(e.output[i], "\t", "    "), cols))
+	}
+	return screen
+}
+
+// styleState colors text according to a repository state
+func styleState(state, text string) string {
+	switch state {
+	case report.StatusFailed:
+		return colors.Error(text)
+	case report.StatusSkipped:
+		return colors.Warning(text)
+	case stateRunning:
+		return colors.Info(text)
+	case stateQueued:
+		return colors.Dim(text)
+	}
+	return colors.Success(text)
+}
+
+// splitLines splits command output into lines
+func splitLines(output string) []string {
+	return strings.Split(strings.TrimRight(output, "\n"), "\n")
+}
@@ -0,0 +1,162 @@
+package report
+
+import (
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+)
+
+// htmlRow is a single repository row of the HTML report
+type htmlRow struct {
+	Name       string
+	Path       string
+	Status     string
+	StatusText string
+	StatusRank int
+	Duration   string
+	DurationMS int64
+	Details    string
+	Output     string
+}
+
+// htmlTemplate renders a self-contained report without external assets
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>rgp: {{.Command}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+h1 code { font-size: 0.9em; }
+.meta { color: #57606a; margin-bottom: 1.5em; }
+.counts span { margin-right: 1.5em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 6px 10px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; }
+th.asc::after { content: " \25B2"; }
+th.desc::after { content: " \25BC"; }
+pre { margin: 0.5em 0 0; white-space: pre-wrap; font-size: 0.85em; }
+summary { cursor: pointer; color: #57606a; }
+.failed { color: #cf222e; font-weight: bold; }
+.skipped { color: #9a6700; font-weight: bold; }
+.updated { color: #0969da; font-weight: bold; }
+.up-to-date, .succeeded { color: #1a7f37; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>rgp: <code>{{.Command}}</code></h1>
+<div class="meta">
+<div>Root path: <code>{{.RootPath}}</code></div>
+<div>Started: {{.Started}} &middot; Duration: {{.Duration}}</div>
+<div class="counts">{{range .Counts}}<span class="{{.Class}}">{{.Title}}: {{.Count}}</span>{{end}}</div>
+</div>
+<table id="results">
+<thead>
+<tr><th data-type="text">Repository</th><th data-type="text">Path</th><th data-type="number">Status</th><th data-type="number">Duration</th><th data-type="text">Details</th></tr>
+</thead>
+<tbody>
+{{range .Rows}}<tr>
+<td data-value="{{.Name}}">{{.Name}}</td>
+<td data-value="{{.Path}}"><code>{{.Path}}</code></td>
+<td data-value="{{.StatusRank}}" class="{{.Status}}">{{.StatusText}}</td>
+<td data-value="{{.DurationMS}}">{{.Duration}}</td>
+<td data-value="{{.Details}}">{{.Details}}{{if .Output}}<details><summary>Output</summary><pre>{{.Output}}</pre></details>{{end}}</td>
+</tr>
+{{end}}</tbody>
+</table>
+<script>
+(function () {
+  var table = document.getElementById("results");
+  var headers = table.querySelectorAll("th");
+  headers.forEach(function (th, column) {
+    th.addEventListener("click", function () {
+      var ascending = !th.classList.contains("asc");
+      headers.forEach(function (h) { h.classList.remove("asc", "desc"); });
+      th.classList.add(ascending ? "asc" : "desc");
+      var numeric = th.dataset.type === "number";
+      var body = table.tBodies[0];
+      var rows = Array.prototype.slice.call(body.rows);
+      rows.sort(function (a, b) {
+        var x = a.cells[column].dataset.value, y = b.cells[column].dataset.value;
+        var order = numeric ? Number(x) - Number(y) : x.localeCompare(y);
+        return ascending ? order : -order;
+      });
+      rows.forEach(function (row) { body.appendChild(row); });
+    });
+  });
+})();
+</script>
+</body>
+</html>
+`))
+
+// htmlCount is a per-status counter shown in the report header
+type htmlCount struct {
+	Class string
+	Title string
+	Count int
+}
+
+// WriteHTML renders the run as a static HTML page with a sortable table
+// and collapsible output for each repository
+func WriteHTML(w io.Writer, run *Run) error {
+	results := run.sortedResults()
+	groups := groupByStatus(results)
+
+	rank := make(map[string]int, len(statusOrder))
+	var counts []htmlCount
+	for i, status := range statusOrder {
+		rank[status] = i
+		if len(groups[status]) > 0 {
+			counts = append(counts, htmlCount{Class: cssClass(status), Title: statusTitles[status], Count: len(groups[status])})
+		}
+	}
+
+	rows := make([]htmlRow, 0, len(results))
+	for _, result := range results {
+		status := Status(result)
+		rows = append(rows, htmlRow{
+			Name:       result.Repository.Name,
+			Path:       result.Repository.Path,
+			Status:     cssClass(status),
+			StatusText: status,
+			StatusRank: rank[status],
+			Duration:   result.Duration.Round(time.Millisecond).String(),
+			DurationMS: result.Duration.Milliseconds(),
+			Details:    details(result),
+			Output:     strings.TrimSpace(result.Output),
+		})
+	}
+
+	return htmlTemplate.Execute(w, struct {
+		Command  string
+		RootPath string
+		Started  string
+		Duration string
+		Counts   []htmlCount
+		Rows     []htmlRow
+	}{
+		Command:  git.DisplayCommand(run.Command),
+		RootPath: run.RootPath,
+		Started:  run.Started.Format("2006-01-02 15:04:05"),
+		Duration: run.Duration.Round(time.Millisecond).String(),
+		Counts:   counts,
+		Rows:     rows,
+	})
+}
+
+// WriteHTMLFile writes the HTML report to path
+func WriteHTMLFile(path string, run *Run) error {
+	return writeFile(path, func(f *os.File) error {
+		return WriteHTML(f, run)
+	})
+}
+
+// cssClass converts a status into a CSS class name
+func cssClass(status string) string {
+	return strings.ReplaceAll(status, " ", "-")
+}
@@ -0,0 +1,149 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// Types of the failure and skipped elements. They are short and stable so CI
+// tools can group test cases by them; the error itself is in the message.
+const (
+	junitTypeError   = "error"
+	junitTypeTimeout = "timeout"
+	junitTypeSkipped = "skipped"
+)
+
+// WriteJUnit renders the run as JUnit XML, with one test case per repository
+func WriteJUnit(w io.Writer, run *Run) error {
+	suite := junitTestSuite{
+		Name:      "rgp: " + git.DisplayCommand(run.Command),
+		Time:      seconds(run.Duration),
+		Timestamp: run.Started.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, result := range run.sortedResults() {
+		testCase := junitTestCase{
+			Name:      result.Repository.Name,
+			ClassName: className(run.RootPath, result.Repository.Path),
+			Time:      seconds(result.Duration),
+			SystemOut: result.Output,
+		}
+
+		switch {
+		case result.Skipped:
+			suite.Skipped++
+			testCase.Skipped = &junitMessage{Message: result.Error, Type: junitTypeSkipped}
+		case !result.Success:
+			suite.Failures++
+			failureType := junitTypeError
+			if result.TimedOut {
+				failureType = junitTypeTimeout
+			}
+			testCase.Failure = &junitMessage{
+				Message: failureMessage(result.Stderr, result.Error),
+				Type:    failureType,
+				Body:    result.Stderr,
+			}
+		}
+
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteJUnitFile writes the JUnit XML report to path
+func WriteJUnitFile(path string, run *Run) error {
+	return writeFile(path, func(f *os.File) error {
+		return WriteJUnit(f, run)
+	})
+}
+
+// failureMessage returns the full error followed by the most relevant line
+// of stderr, if any
+func failureMessage(stderr, errText string) string {
+	relevant := ""
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "error:") {
+			relevant = line
+			break
+		}
+		if relevant == "" {
+			relevant = line
+		}
+	}
+
+	switch {
+	case relevant == "" || relevant == errText:
+		return errText
+	case errText == "":
+		return relevant
+	default:
+		return errText + ": " + relevant
+	}
+}
+
+// className derives a JUnit class name from the repository path relative to the root
+func className(rootPath, repoPath string) string {
+	relPath, err := filepath.Rel(rootPath, repoPath)
+	if err != nil {
+		relPath = filepath.Base(repoPath)
+	}
+	return "rgp." + strings.ReplaceAll(filepath.ToSlash(relPath), "/", ".")
+}
+
+// seconds formats a duration as fractional seconds
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,105 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	repo := func(name string) *types.Repository {
+		return &types.Repository{Name: name, Path: "/work/team/" + name}
+	}
+	run := &Run{
+		Command:  "pull",
+		RootPath: "/work",
+		Started:  time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC),
+		Duration: 1500 * time.Millisecond,
+		Results: []*types.ExecutionResult{
+			{Repository: repo("ok"), Success: true, Output: "Already up to date.", Duration: 100 * time.Millisecond},
+			{Repository: repo("failed"), Error: "exit status 1", Stderr: "hint: something\nfatal: not possible to fast-forward\n"},
+			{Repository: repo("timeout"), Error: "Command timed out after 30s", TimedOut: true},
+			{Repository: repo("dirty"), Error: "Repository has unstaged changes (skipped)", Skipped: true},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, run); err != nil {
+		t.Fatal(err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if len(report.Suites) != 1 {
+		t.Fatalf("got %d test suites, want 1", len(report.Suites))
+	}
+
+	suite := report.Suites[0]
+	if suite.Name != "rgp: git pull" || suite.Tests != 4 || suite.Failures != 2 || suite.Skipped != 1 || suite.Time != "1.500" {
+		t.Errorf("suite = %+v", suite)
+	}
+
+	cases := make(map[string]junitTestCase)
+	for _, testCase := range suite.TestCases {
+		cases[testCase.Name] = testCase
+	}
+
+	tests := []struct {
+		name     string
+		failure  *junitMessage
+		skipped  *junitMessage
+		class    string
+		duration string
+	}{
+		{name: "ok", class: "rgp.team.ok", duration: "0.100"},
+		{
+			name:     "failed",
+			failure:  &junitMessage{Message: "exit status 1: fatal: not possible to fast-forward", Type: junitTypeError, Body: "hint: something\nfatal: not possible to fast-forward\n"},
+			class:    "rgp.team.failed",
+			duration: "0.000",
+		},
+		{
+			name:     "timeout",
+			failure:  &junitMessage{Message: "Command timed out after 30s", Type: junitTypeTimeout},
+			class:    "rgp.team.timeout",
+			duration: "0.000",
+		},
+		{
+			name:     "dirty",
+			skipped:  &junitMessage{Message: "Repository has unstaged changes (skipped)", Type: junitTypeSkipped},
+			class:    "rgp.team.dirty",
+			duration: "0.000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testCase, ok := cases[tt.name]
+			if !ok {
+				t.Fatalf("no test case for %s", tt.name)
+			}
+			if testCase.ClassName != tt.class || testCase.Time != tt.duration {
+				t.Errorf("classname, time = %q, %q, want %q, %q", testCase.ClassName, testCase.Time, tt.class, tt.duration)
+			}
+			if !equalMessages(testCase.Failure, tt.failure) {
+				t.Errorf("failure = %+v, want %+v", testCase.Failure, tt.failure)
+			}
+			if !equalMessages(testCase.Skipped, tt.skipped) {
+				t.Errorf("skipped = %+v, want %+v", testCase.Skipped, tt.skipped)
+			}
+		})
+	}
+}
+
+// equalMessages compares two optional JUnit messages
+func equalMessages(a, b *junitMessage) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+)
+
+// logIndexFile is the name of the index written to the log directory
+const logIndexFile = "index.log"
+
+// WriteLogDir writes the full output of every repository to its own file in
+// a new directory for the run inside dir, plus an index file, and records
+// each file path in the results. The run directory is named after the run
+// start time, like the run ID in the history, so logs of earlier runs are
+// kept apart. It returns the run directory.
+func WriteLogDir(dir string, run *Run) (string, error) {
+	dir = filepath.Join(dir, run.Started.Format("20060102-150405.000"))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	var index strings.Builder
+	fmt.Fprintf(&index, "Command:  %s\n", git.DisplayCommand(run.Command))
+	fmt.Fprintf(&index, "Root:     %s\n", run.RootPath)
+	fmt.Fprintf(&index, "Started:  %s\n", run.Started.Format(time.RFC3339))
+	fmt.Fprintf(&index, "Duration: %v\n\n", run.Duration)
+
+	for _, result := range run.sortedResults() {
+		path := filepath.Join(dir, logFileName(run.RootPath, result.Repository.Path))
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "Repository: %s\n", result.Repository.Name)
+		fmt.Fprintf(&b, "Path:       %s\n", result.Repository.Path)
+		fmt.Fprintf(&b, "Command:    %s\n", git.DisplayCommand(result.Command))
+		fmt.Fprintf(&b, "Status:     %s\n", Status(result))
+		fmt.Fprintf(&b, "Duration:   %v\n", result.Duration)
+		if result.Error != "" {
+			fmt.Fprintf(&b, "Error:      %s\n", result.Error)
+		}
+		b.WriteString("\n")
+		b.WriteString(result.Output)
+
+		if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+			return "", err
+		}
+		result.LogFile = path
+
+		fmt.Fprintf(&index, "%-12s %-30s %s\n", Status(result), result.Repository.Name, path)
+	}
+
+	return dir, os.WriteFile(filepath.Join(dir, logIndexFile), []byte(index.String()), 0o644)
+}
+
+// logFileName derives a unique file name from the repository path relative
+// to the root. The path is escaped rather than flattened so different paths
+// never share a name: "a/b" becomes "a%2Fb.log" and "a__b" stays "a__b.log".
+func logFileName(rootPath, repoPath string) string {
+	relPath, err := filepath.Rel(rootPath, repoPath)
+	if err != nil || strings.HasPrefix(relPath, "..") {
+		relPath = repoPath
+	}
+	relPath = strings.Trim(filepath.ToSlash(relPath), "/")
+	if relPath == "" || relPath == "." {
+		relPath = filepath.Base(repoPath)
+	}
+	return url.PathEscape(relPath) + ".log"
+}
@@ -0,0 +1,58 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+)
+
+// WriteMarkdown renders the run as Markdown, with one table per result status
+func WriteMarkdown(w io.Writer, run *Run) error {
+	results := run.sortedResults()
+	groups := groupByStatus(results)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# rgp: `%s`\n\n", git.DisplayCommand(run.Command))
+	fmt.Fprintf(&b, "- **Root path:** `%s`\n", run.RootPath)
+	fmt.Fprintf(&b, "- **Started:** %s\n", run.Started.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&b, "- **Duration:** %v\n", run.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&b, "- **Repositories:** %d\n", len(results))
+
+	for _, status := range statusOrder {
+		group := groups[status]
+		if len(group) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n## %s (%d)\n\n", statusTitles[status], len(group))
+		b.WriteString("| Repository | Path | Duration | Details |\n")
+		b.WriteString("|---|---|---|---|\n")
+		for _, result := range group {
+			fmt.Fprintf(&b, "| %s | `%s` | %v | %s |\n",
+				markdownCell(result.Repository.Name),
+				markdownCell(result.Repository.Path),
+				result.Duration.Round(time.Millisecond),
+				markdownCell(details(result)))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMarkdownFile writes the Markdown report to path
+func WriteMarkdownFile(path string, run *Run) error {
+	return writeFile(path, func(f *os.File) error {
+		return WriteMarkdown(f, run)
+	})
+}
+
+// markdownCell escapes text for use inside a Markdown table cell
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(strings.TrimSpace(text), "|", "\\|")
+	return strings.Join(strings.Fields(text), " ")
+}
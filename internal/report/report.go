@@ -0,0 +1,42 @@
+package report
+
+import (
+	"os"
+	"sort"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// Run describes a completed execution for report writers
+type Run struct {
+	Command  string
+	RootPath string
+	Started  time.Time
+	Duration time.Duration
+	Results  []*types.ExecutionResult
+}
+
+// sortedResults returns the results ordered by repository name
+func (r *Run) sortedResults() []*types.ExecutionResult {
+	results := make([]*types.ExecutionResult, len(r.Results))
+	copy(results, r.Results)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Repository.Name < results[j].Repository.Name
+	})
+	return results
+}
+
+// writeFile writes a report to path using the given writer function
+func writeFile(path string, write func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
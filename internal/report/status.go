@@ -0,0 +1,89 @@
+package report
+
+import (
+	"fmt"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// Result statuses used to group repositories in reports
+const (
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+	StatusUpdated   = "updated"
+	StatusUpToDate  = "up to date"
+	StatusSucceeded = "succeeded"
+)
+
+// statusOrder lists statuses in the order reports present them
+var statusOrder = []string{StatusFailed, StatusSkipped, StatusUpdated, StatusUpToDate, StatusSucceeded}
+
+// statusTitles holds the section title for each status
+var statusTitles = map[string]string{
+	StatusFailed:    "Failed",
+	StatusSkipped:   "Skipped",
+	StatusUpdated:   "Updated",
+	StatusUpToDate:  "Already up to date",
+	StatusSucceeded: "Succeeded",
+}
+
+// Status classifies a result for reporting
+func Status(result *types.ExecutionResult) string {
+	switch {
+	case result.Skipped:
+		return StatusSkipped
+	case !result.Success:
+		return StatusFailed
+	case result.Changes.Updated():
+		return StatusUpdated
+	case result.Changes != nil:
+		return StatusUpToDate
+	default:
+		return StatusSucceeded
+	}
+}
+
+// groupByStatus groups results by status, keeping their order within each group
+func groupByStatus(results []*types.ExecutionResult) map[string][]*types.ExecutionResult {
+	groups := make(map[string][]*types.ExecutionResult)
+	for _, result := range results {
+		status := Status(result)
+		groups[status] = append(groups[status], result)
+	}
+	return groups
+}
+
+// details returns a one-line description of a result: its error or what it changed
+func details(result *types.ExecutionResult) string {
+	if result.Error != "" {
+		return result.Error
+	}
+	if result.Changes.Updated() {
+		return DescribeChanges(result.Changes)
+	}
+	return ""
+}
+
+// DescribeChanges returns a short description of what a command changed
+func DescribeChanges(changes *types.ChangeSummary) string {
+	return fmt.Sprintf("%s..%s %s, %s, %s changed, +%d -%d",
+		shortSHA(changes.OldHead), shortSHA(changes.NewHead), changes.Kind,
+		plural(changes.IncomingCommits, "commit"), plural(changes.FilesChanged, "file"),
+		changes.Insertions, changes.Deletions)
+}
+
+// plural returns the count followed by the noun, in plural unless count is 1
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// shortSHA abbreviates a commit hash
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,65 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+func TestDescribeChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes *types.ChangeSummary
+		want    string
+	}{
+		{
+			name: "plural",
+			changes: &types.ChangeSummary{
+				OldHead: "0abb4b2c9e", NewHead: "ab554dbf31", Kind: types.UpdateFastForward,
+				IncomingCommits: 3, FilesChanged: 5, Insertions: 42, Deletions: 7,
+			},
+			want: "0abb4b2..ab554db fast-forward, 3 commits, 5 files changed, +42 -7",
+		},
+		{
+			name: "singular",
+			changes: &types.ChangeSummary{
+				OldHead: "0abb4b2c9e", NewHead: "ab554dbf31", Kind: types.UpdateMerge,
+				IncomingCommits: 1, FilesChanged: 1, Insertions: 1,
+			},
+			want: "0abb4b2..ab554db merge, 1 commit, 1 file changed, +1 -0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DescribeChanges(tt.changes); got != tt.want {
+				t.Errorf("DescribeChanges() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	updated := &types.ChangeSummary{OldHead: "a", NewHead: "b", Kind: types.UpdateFastForward}
+	unchanged := &types.ChangeSummary{OldHead: "a", NewHead: "a", Kind: types.UpdateNone}
+
+	tests := []struct {
+		name   string
+		result *types.ExecutionResult
+		want   string
+	}{
+		{"skipped", &types.ExecutionResult{Skipped: true}, StatusSkipped},
+		{"failed", &types.ExecutionResult{Error: "exit status 1"}, StatusFailed},
+		{"updated", &types.ExecutionResult{Success: true, Changes: updated}, StatusUpdated},
+		{"up to date", &types.ExecutionResult{Success: true, Changes: unchanged}, StatusUpToDate},
+		{"no changes recorded", &types.ExecutionResult{Success: true}, StatusSucceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Status(tt.result); got != tt.want {
+				t.Errorf("Status() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Package selector implements the interactive checklist used to pick the
+// repositories a command runs on.
+package selector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/colors"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/terminal"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// ErrCancelled is returned when the user leaves the checklist without confirming
+var ErrCancelled = errors.New("selection cancelled")
+
+// headerLines is the number of lines drawn above the list
+const headerLines = 3
+
+// item is a repository in the checklist
+type item struct {
+	repo     *types.Repository
+	label    string
+	selected bool
+}
+
+// checklist holds the state of the interactive checklist
+type checklist struct {
+	items   []*item
+	visible []*item
+	filter  string
+	cursor  int
+	offset  int
+}
+
+// Select shows a filterable checklist of the repositories on the terminal
+// and returns the selected ones in their original order. Repositories whose
+// path is in preselected start checked. Labels are paths relative to root.
+//
+// Keys: type to filter, up/down to move, space to toggle, ctrl-a to toggle
+// all visible repositories, enter to confirm, esc or ctrl-c to cancel.
+// Confirming with nothing checked selects the highlighted repository.
+func Select(repositories []*types.Repository, root string, preselected []string) ([]*types.Repository, error) {
+	checked := make(map[string]bool, len(preselected))
+	for _, path := range preselected {
+		checked[path] = true
+	}
+
+	c := &checklist{}
+	for _, repo := range repositories {
+		label := repo.Name
+		if relative, err := filepath.Rel(root, repo.Path); err == nil {
+			label = relative
+		}
+		c.items = append(c.items, &item{repo: repo, label: label, selected: checked[absPath(repo.Path)]})
+	}
+	c.applyFilter()
+
+	restore, err := terminal.MakeRaw()
+	if err != nil {
+		return nil, err
+	}
+	out := os.Stderr
+	fmt.Fprint(out, terminal.EnterAltScreen+terminal.HideCursor)
+	defer func() {
+		fmt.Fprint(out, terminal.ShowCursor+terminal.ExitAltScreen)
+		restore()
+	}()
+
+	in := bufio.NewReader(os.Stdin)
+	for {
+		rows, cols := terminal.Size()
+		if err := c.render(rows, cols).Flush(out); err != nil {
+			return nil, err
+		}
+
+		key, char, err := terminal.ReadKey(in)
+		if err != nil {
+			return nil, err
+		}
+
+		switch key {
+		case terminal.KeyEscape, terminal.KeyCtrlC:
+			return nil, ErrCancelled
+		case terminal.KeyEnter:
+			if selected := c.selected(); len(selected) > 0 {
+				return selected, nil
+			}
+			if len(c.visible) > 0 {
+				return []*types.Repository{c.visible[c.cursor].repo}, nil
+			}
+		case terminal.KeyUp, terminal.KeyCtrlP:
+			c.move(-1)
+		case terminal.KeyDown, terminal.KeyCtrlN, terminal.KeyTab:
+			c.move(1)
+		case terminal.KeyPageUp:
+			c.move(-(rows - headerLines))
+		case terminal.KeyPageDown:
+			c.move(rows - headerLines)
+		case terminal.KeyHome:
+			c.move(-len(c.visible))
+		case terminal.KeyEnd:
+			c.move(len(c.visible))
+		case terminal.KeyCtrlA:
+			c.toggleVisible()
+		case terminal.KeyBackspace:
+			if c.filter != "" {
+				runes := []rune(c.filter)
+				c.filter = string(runes[:len(runes)-1])
+				c.applyFilter()
+			}
+		case terminal.KeyCtrlU:
+			c.filter = ""
+			c.applyFilter()
+		case terminal.KeyRune:
+			if char == ' ' {
+				if len(c.visible) > 0 {
+					current := c.visible[c.cursor]
+					current.selected = !current.selected
+					c.move(1)
+				}
+			} else {
+				c.filter += string(char)
+				c.applyFilter()
+			}
+		}
+	}
+}
+
+// applyFilter updates the visible items after the filter changed
+func (c *checklist) applyFilter() {
+	c.visible = c.visible[:0]
+	for _, it := range c.items {
+		if fuzzyMatch(c.filter, it.label) {
+			c.visible = append(c.visible, it)
+		}
+	}
+	c.cursor = 0
+	c.offset = 0
+}
+
+// move moves the cursor by delta, staying within the visible items
+func (c *checklist) move(delta int) {
+	c.cursor += delta
+	if c.cursor >= len(c.visible) {
+		c.cursor = len(c.visible) - 1
+	}
+	if c.cursor < 0 {
+		c.cursor = 0
+	}
+}
+
+// toggleVisible checks all visible items, or unchecks them if all are checked
+func (c *checklist) toggleVisible() {
+	all := true
+	for _, it := range c.visible {
+		all = all && it.selected
+	}
+	for _, it := range c.visible {
+		it.selected = !all
+	}
+}
+
+// selected returns the checked repositories in their original order
+func (c *checklist) selected() []*types.Repository {
+	var repositories []*types.Repository
+	for _, it := range c.items {
+		if it.selected {
+			repositories = append(repositories, it.repo)
+		}
+	}
+	return repositories
+}
+
+// render draws the checklist for a terminal of the given size
+func (c *checklist) render(rows, cols int) *terminal.Screen {
+	count := 0
+	for _, it := range c.items {
+		if it.selected {
+			count++
+		}
+	}
+
+	screen := &terminal.Screen{}
+	screen.Line(colors.Bold(terminal.Truncate(fmt.Sprintf("Select repositories (%d of %d selected)", count, len(c.items)), cols)))
+	screen.Line(colors.Dim(terminal.Truncate("type to filter, space toggle, ctrl-a toggle all, enter run, esc cancel", cols)))
+	screen.Line(colors.Info("> ") + terminal.Truncate(c.filter, cols-2))
+
+	// Scroll to keep the cursor on screen
+	height := rows - headerLines
+	if height < 1 {
+		height = 1
+	}
+	if c.cursor < c.offset {
+		c.offset = c.cursor
+	}
+	if c.cursor >= c.offset+height {
+		c.offset = c.cursor - height + 1
+	}
+
+	if len(c.visible) == 0 {
+		screen.Line(colors.Warning(terminal.Truncate("No repositories match the filter", cols)))
+	}
+	for i := c.offset; i < len(c.visible) && i < c.offset+height; i++ {
+		it := c.visible[i]
+		box := "[ ] "
+		if it.selected {
+			box = "[x] "
+		}
+		line := terminal.Truncate(box+it.label, cols-2)
+		if i == c.cursor {
+			screen.Line(colors.Info("> ") + terminal.Reverse + line + terminal.Reset)
+		} else if it.selected {
+			screen.Line("  " + colors.Success(line))
+		} else {
+			screen.Line("  " + line)
+		}
+	}
+	return screen
+}
+
+// fuzzyMatch checks if the characters of pattern appear in text in order,
+// ignoring case
+func fuzzyMatch(pattern, text string) bool {
+	remaining := []rune(strings.ToLower(pattern))
+	for _, char := range strings.ToLower(text) {
+		if len(remaining) == 0 {
+			break
+		}
+		if unicode.ToLower(char) == remaining[0] {
+			remaining = remaining[1:]
+		}
+	}
+	return len(remaining) == 0
+}
+
+// absPath returns the absolute form of path, or path itself if it cannot be resolved
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
@@ -0,0 +1,73 @@
+package selector
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// DefaultPath returns the file remembering the last selection of each
+// workspace, in the user data directory
+func DefaultPath() (string, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataDir, "rgp", "selections.json"), nil
+}
+
+// Load returns the absolute paths of the repositories last selected in the
+// workspace. A missing file is not an error.
+func Load(path, workspace string) ([]string, error) {
+	selections, err := loadAll(path)
+	if err != nil {
+		return nil, err
+	}
+	return selections[absPath(workspace)], nil
+}
+
+// Save remembers the repositories selected in the workspace
+func Save(path, workspace string, repositories []*types.Repository) error {
+	selections, err := loadAll(path)
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(repositories))
+	for _, repo := range repositories {
+		paths = append(paths, absPath(repo.Path))
+	}
+	selections[absPath(workspace)] = paths
+
+	data, err := json.MarshalIndent(selections, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// loadAll reads the selections of all workspaces
+func loadAll(path string) (map[string][]string, error) {
+	selections := make(map[string][]string)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return selections, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &selections); err != nil {
+		return nil, err
+	}
+	return selections, nil
+}
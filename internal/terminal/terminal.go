@@ -0,0 +1,231 @@
+// Package terminal provides the raw-mode input and screen control used by
+// the interactive views. Raw mode is set through stty, so it requires a
+// Unix-like system.
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Escape sequences for screen control
+const (
+	EnterAltScreen = "\033[?1049h"
+	ExitAltScreen  = "\033[?1049l"
+	HideCursor     = "\033[?25l"
+	ShowCursor     = "\033[?25h"
+	ClearScreen    = "\033[H\033[2J"
+	ClearLine      = "\033[K"
+	Reverse        = "\033[7m"
+	Reset          = "\033[0m"
+)
+
+// Key identifies a key press
+type Key int
+
+// Keys returned by ReadKey. Printable characters are returned as KeyRune.
+const (
+	KeyUnknown Key = iota
+	KeyRune
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyTab
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyCtrlA
+	KeyCtrlC
+	KeyCtrlD
+	KeyCtrlN
+	KeyCtrlP
+	KeyCtrlU
+)
+
+// IsTerminal checks if f is connected to a terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// MakeRaw puts the terminal connected to stdin in raw mode and returns a
+// function restoring its previous state
+func MakeRaw() (func(), error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read terminal state: %v", err)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("cannot set terminal to raw mode: %v", err)
+	}
+	return func() { stty(strings.TrimSpace(state)) }, nil
+}
+
+// Size returns the number of rows and columns of the terminal connected to
+// stdin, defaulting to 24x80
+func Size() (int, int) {
+	output, err := stty("size")
+	if err == nil {
+		fields := strings.Fields(output)
+		if len(fields) == 2 {
+			rows, rowsErr := strconv.Atoi(fields[0])
+			cols, colsErr := strconv.Atoi(fields[1])
+			if rowsErr == nil && colsErr == nil && rows > 0 && cols > 0 {
+				return rows, cols
+			}
+		}
+	}
+	return 24, 80
+}
+
+// stty runs stty on the terminal connected to stdin
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return string(output), err
+}
+
+// ReadKey reads a single key press from r, decoding escape sequences.
+// The rune is only meaningful for KeyRune.
+func ReadKey(r *bufio.Reader) (Key, rune, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return KeyUnknown, 0, err
+	}
+
+	switch b {
+	case '\r', '\n':
+		return KeyEnter, 0, nil
+	case '\t':
+		return KeyTab, 0, nil
+	case 127, 8:
+		return KeyBackspace, 0, nil
+	case 1:
+		return KeyCtrlA, 0, nil
+	case 3:
+		return KeyCtrlC, 0, nil
+	case 4:
+		return KeyCtrlD, 0, nil
+	case 14:
+		return KeyCtrlN, 0, nil
+	case 16:
+		return KeyCtrlP, 0, nil
+	case 21:
+		return KeyCtrlU, 0, nil
+	case 27:
+		return readEscape(r)
+	}
+
+	if b < 32 {
+		return KeyUnknown, 0, nil
+	}
+
+	// Decode multi-byte UTF-8 characters
+	if b >= utf8.RuneSelf {
+		buf := []byte{b}
+		for !utf8.FullRune(buf) && len(buf) < utf8.UTFMax {
+			next, err := r.ReadByte()
+			if err != nil {
+				return KeyUnknown, 0, err
+			}
+			buf = append(buf, next)
+		}
+		char, _ := utf8.DecodeRune(buf)
+		return KeyRune, char, nil
+	}
+	return KeyRune, rune(b), nil
+}
+
+// readEscape decodes the rest of an escape sequence. A lone escape is
+// reported as KeyEscape.
+func readEscape(r *bufio.Reader) (Key, rune, error) {
+	if r.Buffered() == 0 {
+		return KeyEscape, 0, nil
+	}
+
+	b, err := r.ReadByte()
+	if err != nil {
+		return KeyEscape, 0, nil
+	}
+	if b != '[' && b != 'O' {
+		return KeyEscape, 0, nil
+	}
+
+	var seq []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return KeyUnknown, 0, err
+		}
+		seq = append(seq, c)
+		if c >= 0x40 && c <= 0x7e {
+			break
+		}
+	}
+
+	switch string(seq) {
+	case "A":
+		return KeyUp, 0, nil
+	case "B":
+		return KeyDown, 0, nil
+	case "C":
+		return KeyRight, 0, nil
+	case "D":
+		return KeyLeft, 0, nil
+	case "H", "1~", "7~":
+		return KeyHome, 0, nil
+	case "F", "4~", "8~":
+		return KeyEnd, 0, nil
+	case "5~":
+		return KeyPageUp, 0, nil
+	case "6~":
+		return KeyPageDown, 0, nil
+	}
+	return KeyUnknown, 0, nil
+}
+
+// Truncate shortens text to at most width characters
+func Truncate(text string, width int) string {
+	if width < 0 {
+		width = 0
+	}
+	if utf8.RuneCountInString(text) > width {
+		return string([]rune(text)[:width])
+	}
+	return text
+}
+
+// Screen buffers the lines of a frame and writes it in a single call.
+// Lines end with CRLF because output processing is disabled in raw mode.
+type Screen struct {
+	lines []string
+}
+
+// Line adds a line to the frame. Text wider than the terminal must be
+// truncated by the caller before styling it.
+func (s *Screen) Line(text string) {
+	s.lines = append(s.lines, text)
+}
+
+// Flush draws the frame from the top of the screen to w
+func (s *Screen) Flush(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("\033[H")
+	b.WriteString(strings.Join(s.lines, ClearLine+"\r\n"))
+	b.WriteString(ClearLine + "\033[J")
+	s.lines = s.lines[:0]
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,23 @@
+package rgp
+
+import "github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+
+// Event reports the progress of a repository during a run
+type Event = git.Event
+
+// EventType identifies what an Event reports
+type EventType = git.EventType
+
+// EventHandler receives events as they happen. It may be called
+// concurrently for different repositories, and blocks the repository's
+// work until it returns.
+type EventHandler = git.EventHandler
+
+// Event types, in the order they occur for a repository
+const (
+	EventQueued     = git.EventQueued
+	EventRetrying   = git.EventRetrying
+	EventStarted    = git.EventStarted
+	EventOutputLine = git.EventOutputLine
+	EventFinished   = git.EventFinished
+)
@@ -0,0 +1,230 @@
+package rgp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/config"
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/git"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// Pull strategies accepted by WithPullStrategy
+const (
+	PullStrategyFFOnly = types.PullStrategyFFOnly
+	PullStrategyRebase = types.PullStrategyRebase
+)
+
+// Dirty states accepted by WithDirtyStates
+const (
+	DirtyStaged    = types.DirtyStaged
+	DirtyUnstaged  = types.DirtyUnstaged
+	DirtyUntracked = types.DirtyUntracked
+	DirtyRebase    = types.DirtyRebase
+)
+
+// ErrCancelled is the error of repositories cancelled with Executor.Cancel
+var ErrCancelled = git.ErrCancelled
+
+// Executor runs commands across repositories. It is safe for concurrent
+// use, but a repository should only be in one run at a time. Handlers
+// given with WithEventHandler or AddEventHandler receive the events of
+// every run, while Stream only delivers the events of its own run.
+type Executor struct {
+	executor *git.Executor
+}
+
+// Option configures an Executor
+type Option func(*options)
+
+// options holds the settings collected from the options of NewExecutor
+type options struct {
+	config   *types.Config
+	handlers []EventHandler
+}
+
+// NewExecutor creates an executor with the same defaults as the command
+// line tool: 4 parallel workers and a 30s timeout per git command.
+// Commands run in repositories with local changes as well; see
+// WithSkipDirty and WithAutostash.
+func NewExecutor(opts ...Option) (*Executor, error) {
+	o := &options{config: config.Default()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if err := validate(o.config); err != nil {
+		return nil, err
+	}
+
+	executor := git.NewExecutor(o.config)
+	for _, handler := range o.handlers {
+		executor.AddEventHandler(handler)
+	}
+	return &Executor{executor: executor}, nil
+}
+
+// validate checks the settings of an executor
+func validate(c *types.Config) error {
+	if c.MaxWorkers <= 0 {
+		return errors.New("number of workers must be positive")
+	}
+	if c.MaxFailures < 0 {
+		return errors.New("maximum number of failures cannot be negative")
+	}
+	if c.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	if c.RepoTimeout < 0 || c.RunTimeout < 0 {
+		return errors.New("deadlines cannot be negative")
+	}
+	if c.PullStrategy != types.PullStrategyFFOnly && c.PullStrategy != types.PullStrategyRebase {
+		return fmt.Errorf("invalid pull strategy '%s' (expected rebase or ff-only)", c.PullStrategy)
+	}
+	for _, state := range c.DirtyStates {
+		if !isDirtyState(state) {
+			return fmt.Errorf("invalid dirty state '%s' (expected one of: %s)", state, strings.Join(types.AllDirtyStates, ", "))
+		}
+	}
+	for pattern, limit := range c.HostLimits {
+		if limit <= 0 {
+			return fmt.Errorf("limit for '%s' must be a positive number", pattern)
+		}
+	}
+	return nil
+}
+
+// isDirtyState checks if state is a supported dirty state
+func isDirtyState(state string) bool {
+	for _, valid := range types.AllDirtyStates {
+		if state == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// WithWorkers sets the number of repositories processed at once.
+// One worker processes the repositories sequentially.
+func WithWorkers(n int) Option {
+	return func(o *options) {
+		o.config.MaxWorkers = n
+		o.config.Parallel = n > 1
+	}
+}
+
+// WithTimeout sets the timeout of each git command
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) { o.config.Timeout = d }
+}
+
+// WithRepoTimeout sets a deadline for all steps in a single repository
+func WithRepoTimeout(d time.Duration) Option {
+	return func(o *options) { o.config.RepoTimeout = d }
+}
+
+// WithRunTimeout sets a deadline for an entire run
+func WithRunTimeout(d time.Duration) Option {
+	return func(o *options) { o.config.RunTimeout = d }
+}
+
+// WithSkipDirty skips repositories with local changes when running
+// commands that modify the repository, such as pull or checkout. The
+// changes that count are set with WithDirtyStates.
+func WithSkipDirty() Option {
+	return func(o *options) { o.config.IgnoreDirty = true }
+}
+
+// WithDirtyStates sets which local changes make a repository dirty
+func WithDirtyStates(states ...string) Option {
+	return func(o *options) { o.config.DirtyStates = append([]string(nil), states...) }
+}
+
+// WithAutostash stashes local changes around pulls, optionally including
+// untracked files
+func WithAutostash(untracked bool) Option {
+	return func(o *options) {
+		o.config.Autostash = true
+		o.config.StashUntracked = untracked
+	}
+}
+
+// WithPullStrategy sets how pulls integrate remote changes in autostash
+// mode. It applies to every pull of the run, whether anything was stashed
+// or not.
+func WithPullStrategy(strategy string) Option {
+	return func(o *options) { o.config.PullStrategy = strategy }
+}
+
+// WithAllBranches makes "pull" run "git pull <options> origin <branch>" for
+// every remote branch, merging each one into the current branch
+func WithAllBranches() Option {
+	return func(o *options) { o.config.AllBranches = true }
+}
+
+// WithFailFast stops starting repositories after the first failure
+func WithFailFast() Option {
+	return func(o *options) { o.config.FailFast = true }
+}
+
+// WithMaxFailures stops starting repositories after n failures (0 = no limit)
+func WithMaxFailures(n int) Option {
+	return func(o *options) { o.config.MaxFailures = n }
+}
+
+// WithHostLimits limits how many repositories of matching remote hosts run
+// at once, e.g. {"github.com": 2, "*.internal": 1}
+func WithHostLimits(limits map[string]int) Option {
+	return func(o *options) {
+		o.config.HostLimits = make(map[string]int, len(limits))
+		for pattern, limit := range limits {
+			o.config.HostLimits[strings.ToLower(pattern)] = limit
+		}
+	}
+}
+
+// WithEventHandler registers a handler receiving the events of every run
+func WithEventHandler(handler EventHandler) Option {
+	return func(o *options) { o.handlers = append(o.handlers, handler) }
+}
+
+// Run executes the command on the repositories and returns their results,
+// in the order they finished when running in parallel. Cancelling ctx
+// stops running commands; repositories that did not start are reported
+// as aborted.
+func (e *Executor) Run(ctx context.Context, repositories []*Repository, command string) Results {
+	return e.executor.ExecuteCommandOnRepositories(ctx, repositories, command)
+}
+
+// RunOne executes the command in a single repository
+func (e *Executor) RunOne(ctx context.Context, repo *Repository, command string) *Result {
+	return e.executor.ExecuteCommand(ctx, repo, command)
+}
+
+// Stream runs the command on the repositories in the background and
+// returns a channel receiving the events of the run. The channel is closed
+// after the last event and must be drained.
+func (e *Executor) Stream(ctx context.Context, repositories []*Repository, command string) <-chan Event {
+	return e.executor.Stream(ctx, repositories, command)
+}
+
+// Retry runs the command in the repository again, reporting the attempt
+// with an EventRetrying
+func (e *Executor) Retry(ctx context.Context, repo *Repository, command string) *Result {
+	return e.executor.Retry(ctx, repo, command)
+}
+
+// Cancel stops the command running in the repository, or skips the
+// repository if it is still queued. The repository fails with ErrCancelled.
+// Repositories that are neither running nor queued are left alone.
+func (e *Executor) Cancel(repo *Repository) {
+	e.executor.Cancel(repo)
+}
+
+// AddEventHandler registers a handler receiving the events of every run
+// and returns a function removing it
+func (e *Executor) AddEventHandler(handler EventHandler) func() {
+	return e.executor.AddEventHandler(handler)
+}
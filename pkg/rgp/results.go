@@ -0,0 +1,62 @@
+package rgp
+
+import (
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/report"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// Result is the outcome of a command in one repository
+type Result = types.ExecutionResult
+
+// ChangeSummary describes how a pull moved a repository's HEAD
+type ChangeSummary = types.ChangeSummary
+
+// Result statuses returned by Status
+const (
+	StatusFailed    = report.StatusFailed
+	StatusSkipped   = report.StatusSkipped
+	StatusUpdated   = report.StatusUpdated
+	StatusUpToDate  = report.StatusUpToDate
+	StatusSucceeded = report.StatusSucceeded
+)
+
+// Status classifies a result the way the rgp reports do
+func Status(result *Result) string {
+	return report.Status(result)
+}
+
+// Results are the results of a run
+type Results []*Result
+
+// Failed returns the results of the repositories where the command failed,
+// including timeouts and aborted repositories
+func (r Results) Failed() Results {
+	return r.filter(StatusFailed)
+}
+
+// Skipped returns the results of the repositories that were skipped, e.g.
+// because they had local changes
+func (r Results) Skipped() Results {
+	return r.filter(StatusSkipped)
+}
+
+// Updated returns the results of the repositories whose HEAD moved
+func (r Results) Updated() Results {
+	return r.filter(StatusUpdated)
+}
+
+// OK checks if the command succeeded or was skipped in every repository
+func (r Results) OK() bool {
+	return len(r.Failed()) == 0
+}
+
+// filter returns the results with the given status
+func (r Results) filter(status string) Results {
+	var filtered Results
+	for _, result := range r {
+		if Status(result) == status {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
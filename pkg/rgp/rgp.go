@@ -0,0 +1,74 @@
+// Package rgp runs Git commands across many repositories from Go programs,
+// with the same discovery, safety checks and concurrency as the rgp
+// command line tool.
+//
+// A typical program discovers the repositories under a directory and runs
+// a command on them:
+//
+//	repos, err := rgp.Discover("~/src", rgp.DiscoverOptions{Exclude: []string{"archive-*"}})
+//	if err != nil {
+//		return err
+//	}
+//	executor, err := rgp.NewExecutor(rgp.WithWorkers(8), rgp.WithAutostash(false))
+//	if err != nil {
+//		return err
+//	}
+//	results := executor.Run(ctx, repos, "pull")
+//	for _, result := range results.Failed() {
+//		log.Printf("%s: %s", result.Repository.Name, result.Error)
+//	}
+//
+// Commands are Git arguments such as "pull" or "fetch --prune". Commands
+// built with Shell run through sh instead of git.
+package rgp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/robsonalvesdevbr/recursive-git-pull/internal/finder"
+	"github.com/robsonalvesdevbr/recursive-git-pull/pkg/types"
+)
+
+// Repository is a Git repository found by Discover
+type Repository = types.Repository
+
+// DiscoverOptions selects the repositories returned by Discover
+type DiscoverOptions struct {
+	// Include keeps only repositories whose directory name matches one of
+	// the patterns (filepath.Match syntax). Empty includes everything.
+	Include []string
+
+	// Exclude drops repositories whose directory name matches one of the
+	// patterns
+	Exclude []string
+}
+
+// Discover recursively finds the Git repositories under root, in walk order.
+// A leading "~/" in root is expanded to the home directory.
+func Discover(root string, opts DiscoverOptions) ([]*Repository, error) {
+	if strings.HasPrefix(root, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		root = filepath.Join(home, root[2:])
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path '%s' is not a directory", root)
+	}
+	return finder.FindRepositories(root, opts.Include, opts.Exclude)
+}
+
+// Shell returns a command running script with sh in each repository
+// instead of git
+func Shell(script string) string {
+	return "!" + script
+}
@@ -9,19 +9,108 @@ type Repository struct {
 	Status string
 }
 
+// Pull strategies used when pulling with autostash
+const (
+	PullStrategyRebase = "rebase"
+	PullStrategyFFOnly = "ff-only"
+)
+
+// Repository states that can be counted as dirty
+const (
+	DirtyStaged    = "staged"
+	DirtyUnstaged  = "unstaged"
+	DirtyUntracked = "untracked"
+	DirtyRebase    = "rebase"
+)
+
+// AllDirtyStates lists every supported dirty state
+var AllDirtyStates = []string{DirtyStaged, DirtyUnstaged, DirtyUntracked, DirtyRebase}
+
+// Output modes controlling how results are printed
+const (
+	OutputSummary = "summary"
+	OutputGrouped = "grouped"
+	OutputPrefix  = "prefix"
+	OutputJSON    = "json"
+)
+
+// Formats of the repository list
+const (
+	ListPlain = "plain"
+	ListNUL   = "nul"
+	ListJSON  = "json"
+)
+
+// Columns that can be printed in the repository list
+const (
+	ColumnPath     = "path"
+	ColumnName     = "name"
+	ColumnRelative = "relative"
+	ColumnBranch   = "branch"
+	ColumnRemote   = "remote"
+	ColumnKind     = "kind"
+)
+
+// AllListColumns lists every supported list column
+var AllListColumns = []string{ColumnPath, ColumnName, ColumnRelative, ColumnBranch, ColumnRemote, ColumnKind}
+
 // Config holds configuration for the tool
 type Config struct {
-	RootPath         string
-	Command          string
-	Parallel         bool
-	MaxWorkers       int
-	Timeout          time.Duration
-	IgnoreDirty      bool
-	IncludePatterns  []string
-	ExcludePatterns  []string
-	Verbose          bool
-	AllBranches      bool
-	NoColor          bool
+	RootPath        string
+	Command         string
+	Parallel        bool
+	MaxWorkers      int
+	Timeout         time.Duration
+	RepoTimeout     time.Duration
+	RunTimeout      time.Duration
+	IgnoreDirty     bool
+	DirtyStates     []string
+	IncludePatterns []string
+	ExcludePatterns []string
+	Verbose         bool
+	AllBranches     bool
+	NoColor         bool
+	Theme           string
+	Icons           string
+	Autostash       bool
+	StashUntracked  bool
+	PullStrategy    string
+	FailFast        bool
+	MaxFailures     int
+	HostLimits      map[string]int
+	JUnitReport     string
+	MarkdownReport  string
+	HTMLReport      string
+	LogDir          string
+	OutputMode      string
+	SkipEmpty       bool
+	Quiet           bool
+	ExitCodes       ExitCodes
+	HistoryFile     string
+	NoHistory       bool
+	OnlyFailed      bool
+	Interactive     bool
+	TUI             bool
+	ListFormat      string
+	ListColumns     []string
+	Profile         string
+}
+
+// ExitCodes maps run outcomes to process exit codes
+type ExitCodes struct {
+	Failed       int
+	Skipped      int
+	TimedOut     int
+	NothingFound int
+}
+
+// DefaultExitCodes are the exit codes used unless configured otherwise.
+// Finding no repositories is not an error by default, as in earlier versions.
+var DefaultExitCodes = ExitCodes{
+	Failed:       1,
+	Skipped:      3,
+	TimedOut:     4,
+	NothingFound: 0,
 }
 
 // ExecutionResult represents the result of command execution
@@ -30,6 +119,52 @@ type ExecutionResult struct {
 	Command    string
 	Success    bool
 	Output     string
+	Stderr     string
 	Error      string
 	Duration   time.Duration
-}
\ No newline at end of file
+
+	// NeedsAttention is set when the repository was left in a state that
+	// requires manual intervention, such as a stash that failed to pop
+	NeedsAttention bool
+
+	// TimedOut is set when the command was stopped by a deadline
+	TimedOut bool
+
+	// Skipped is set when the command was not executed in the repository
+	Skipped bool
+
+	// Aborted is set when the repository was skipped because the run was
+	// aborted after reaching the failure threshold
+	Aborted bool
+
+	// LogFile is the file holding the full output, when logs are written
+	LogFile string
+
+	// Changes describes how HEAD moved during a mutating command, or nil
+	// if it was not recorded
+	Changes *ChangeSummary
+}
+
+// Update kinds describing how HEAD moved during a command
+const (
+	UpdateNone        = "no-op"
+	UpdateFastForward = "fast-forward"
+	UpdateMerge       = "merge"
+	UpdateRebase      = "rebase"
+)
+
+// ChangeSummary describes what a command changed in a repository
+type ChangeSummary struct {
+	OldHead         string `json:"old_head"`
+	NewHead         string `json:"new_head"`
+	Kind            string `json:"kind"`
+	IncomingCommits int    `json:"incoming_commits"`
+	FilesChanged    int    `json:"files_changed"`
+	Insertions      int    `json:"insertions"`
+	Deletions       int    `json:"deletions"`
+}
+
+// Updated reports whether HEAD moved
+func (c *ChangeSummary) Updated() bool {
+	return c != nil && c.OldHead != c.NewHead
+}